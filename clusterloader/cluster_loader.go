@@ -18,14 +18,14 @@ package clusterloader
 
 import (
 	"fmt"
-	"io/ioutil"
-	"os"
+	"path"
 	"regexp"
 	"strconv"
 
 	"github.com/onsi/ginkgo"
 	"github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/kubernetes/pkg/api/v1"
 	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
 	"k8s.io/kubernetes/test/e2e/framework"
@@ -34,12 +34,30 @@ import (
 )
 
 var _ = framework.KubeDescribe("Cluster Loader [Feature:ManualPerformance]", func() {
-	f := framework.NewDefaultFramework("cluster-loader")
+	var f *framework.Framework
+	// Runs before the framework creates its namespace and its client, which is limited the same way as the pool
+	ginkgo.BeforeEach(func() {
+		if err := clusterloaderframework.CheckCredentials(); err != nil {
+			framework.Failf("Cluster check failed: %v", err)
+		}
+		if err := clusterloaderframework.Preflight(clusterloaderframework.ConfigContext.ClusterLoader.Preflight); err != nil {
+			framework.Failf("Preflight check failed: %v", err)
+		}
+		clusterloaderframework.SetClientLimits(f)
+		clusterloaderframework.SetupNamespaceNaming()
+		clusterloaderframework.SetupOutputPrintTypes()
+		// Users of shared clusters may not be allowed to create namespaces at all
+		f.SkipNamespaceCreation = clusterloaderframework.ConfigContext.UsesOnlyExistingNamespaces()
+	})
+	f = framework.NewDefaultFramework("cluster-loader")
 	defer ginkgo.GinkgoRecover()
 
 	var c clientset.Interface
 	ginkgo.BeforeEach(func() {
 		c = f.ClientSet
+		if err := clusterloaderframework.SetupClientPool(); err != nil {
+			framework.Failf("Error creating client pool: %v", err)
+		}
 	})
 
 	ginkgo.It(fmt.Sprintf("running config file"), func() {
@@ -49,56 +67,232 @@ var _ = framework.KubeDescribe("Cluster Loader [Feature:ManualPerformance]", fun
 			framework.Failf("invalid config file.\nFile: %v", project)
 		}
 
+		// The timing and retry reports are written even if the run fails, to show how far it got
+		timer := clusterloaderframework.NewTimer()
+		if annotator := clusterloaderframework.GrafanaAnnotator(clusterloaderframework.ConfigContext.ClusterLoader.Grafana); annotator != nil {
+			timer.Observe(annotator)
+		}
+		defer func() {
+			summary := timer.Summary()
+			clusterloaderframework.PrintSummary(summary)
+			clusterloaderframework.PrintSummary(clusterloaderframework.RetriesSummary())
+			clusterloaderframework.PrintSummary(clusterloaderframework.ClientLatenciesSummary())
+			if clusterloaderframework.ConfigContext.ClusterLoader.Timing.Trace && framework.TestContext.ReportDir != "" {
+				if err := summary.WriteTrace(path.Join(framework.TestContext.ReportDir, "trace.json")); err != nil {
+					framework.Logf("Error writing trace: %v", err)
+				}
+			}
+		}()
+
+		// Images with an architecture suffix are rewritten to match the nodes
+		arch, err := clusterloaderframework.DetectArch(c)
+		if err != nil {
+			framework.Failf("Error detecting node architectures: %v", err)
+		}
+
+		if err := clusterloaderframework.CheckFeasibility(c, project, arch); err != nil {
+			framework.Failf("Error checking whether the projects fit the cluster: %v", err)
+		}
+
+		// Warm up the nodes so the measured projects don't start against cold caches
+		warmup := clusterloaderframework.ConfigContext.ClusterLoader.Warmup
+		endWarmup := timer.Start(clusterloaderframework.PhaseSpan, "warmup")
+		if err := clusterloaderframework.Warmup(f, warmup, arch); err != nil {
+			framework.Failf("Error warming up the cluster: %v", err)
+		}
+		endWarmup()
+
+		// Pre-delete hooks run when the test ends, the framework deletes the namespaces right after
+		var preDeleteHooks []namespaceHooks
+		defer func() {
+			if !framework.TestContext.DeleteNamespace {
+				return
+			}
+			for _, h := range preDeleteHooks {
+				if err := clusterloaderframework.RunHooks(h.hooks, h.ns); err != nil {
+					framework.Logf("Error running pre-delete hooks: %v", err)
+				}
+			}
+			// Namespaces are deleted before the framework does to measure how long the deletion takes
+			if framework.TestContext.DeleteNamespaceOnFailure || !ginkgo.CurrentGinkgoTestDescription().Failed {
+				summary, err := clusterloaderframework.DeleteNamespaces(c)
+				if err != nil {
+					framework.Logf("Error deleting namespaces: %v", err)
+				}
+				if summary != nil {
+					clusterloaderframework.PrintSummary(summary)
+				}
+			}
+		}()
+
+		// gatherMeasurements stops the measurements which weren't stopped yet, one failing doesn't
+		// keep the others from being gathered
 		var namespaces []*v1.Namespace
+		var measurements []clusterloaderframework.Measurement
+		var summaries []framework.TestDataSummary
+		gatherMeasurements := func() error {
+			var errs []error
+			for len(measurements) > 0 {
+				measurement := measurements[0]
+				measurements = measurements[1:]
+				summary, err := measurement.Stop(namespaces)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				clusterloaderframework.PrintSummary(summary)
+				summaries = append(summaries, summary)
+			}
+			return utilerrors.NewAggregate(errs)
+		}
+		// When the test fails early the partial results are still written, before the namespaces are deleted
+		defer func() {
+			if err := gatherMeasurements(); err != nil {
+				framework.Logf("Error gathering measurements: %v", err)
+			}
+		}()
+
+		// Start measurements before any object is created
+		for _, config := range clusterloaderframework.ConfigContext.ClusterLoader.Measurements {
+			measurement, err := clusterloaderframework.NewMeasurement(config)
+			if err != nil {
+				framework.Failf("Error creating measurement: %v", err)
+			}
+			if err := measurement.Start(c); err != nil {
+				framework.Failf("Error starting measurement %q: %v", config.Name, err)
+			}
+			measurements = append(measurements, measurement)
+		}
+
+		// The churn runs until the end of the test, it is stopped before the namespaces are deleted
+		churn := clusterloaderframework.ConfigContext.ClusterLoader.Churn
+		churner, err := clusterloaderframework.StartChurn(f, churn, arch)
+		if err != nil {
+			framework.Failf("Error starting churn: %v", err)
+		}
+		if churner != nil {
+			defer func() {
+				clusterloaderframework.PrintSummary(churner.Stop())
+			}()
+		}
+
+		stopProgress := clusterloaderframework.StartProgress(clusterloaderframework.ExpectedObjects(project))
+		defer stopProgress()
 		//totalPods := 0 // Keep track of how many pods for stepping
-		// TODO sjug: add concurrency
 		for _, p := range project {
 			// Find tuning if we have it
 			tuning := clusterloaderframework.TuningSets(tuningSets).Get(p.Tuning)
 
 			framework.Logf("Tuning set is: %+v", tuning)
-			for j := 0; j < p.Number; j++ {
+			clusterloaderframework.SetProgressProject(p.Basename)
+			endProject := timer.Start(clusterloaderframework.PhaseSpan, "project "+p.Basename)
+			if err := clusterloaderframework.SetIdentities(p.Identities); err != nil {
+				framework.Failf("Error creating clients for the identities: %v", err)
+			}
+			// failProject dumps the cluster state for debugging before failing the test
+			failProject := func(format string, args ...interface{}) {
+				clusterloaderframework.DumpClusterState(c, p.Basename, namespaces)
+				framework.Failf(format, args...)
+			}
+			if err := clusterloaderframework.RunProjectExec(p.Exec.Before, p.Basename); err != nil {
+				failProject("Error running project exec: %v", err)
+			}
+			// With project parallelism all namespaces of the project are created up front
+			var projectNamespaces []*v1.Namespace
+			numNamespaces := p.Number
+			if len(p.Namespaces) > 0 {
+				if projectNamespaces, err = clusterloaderframework.GetNamespaces(c, p.Namespaces); err != nil {
+					failProject("Error getting existing NS: %v", err)
+				}
+				numNamespaces = len(projectNamespaces)
+			} else if tuning != nil && tuning.Project.Parallelism > 0 {
+				names := make([]string, p.Number)
+				for j := range names {
+					names[j] = appendIntToString(p.Basename, j)
+				}
+				endNamespaces := timer.Start(clusterloaderframework.PhaseSpan, "namespaces "+p.Basename)
+				projectNamespaces, err = clusterloaderframework.CreateNamespaces(f, names, &tuning.Project)
+				if err != nil {
+					failProject("Error creating NS: %v", err)
+				}
+				endNamespaces()
+			}
+			for j := 0; j < numNamespaces; j++ {
 				// Create namespaces as defined in the config
 				nsName := appendIntToString(p.Basename, j)
-				ns, err := clusterloaderframework.CreateNSIfNotExists(f, nsName)
-				if err != nil {
-					framework.Failf("Error creating NS: %v", err)
+				var ns *v1.Namespace
+				if projectNamespaces != nil {
+					ns = projectNamespaces[j]
+					nsName = ns.Name
+				}
+				endNamespace := timer.Start(clusterloaderframework.NamespaceSpan, nsName)
+				if ns == nil {
+					var projectTuning *clusterloaderframework.TuningSetObject
+					if tuning != nil {
+						projectTuning = &tuning.Project
+					}
+					if ns, err = clusterloaderframework.CreateNSIfNotExists(f, nsName, projectTuning); err != nil {
+						failProject("Error creating NS: %v", err)
+					}
+				}
+				if ns, err = clusterloaderframework.ApplyNamespaceConfig(c, ns, p.Namespace); err != nil {
+					failProject("Error applying namespace labels and annotations: %v", err)
 				}
 				// Keep track of all the namespaces we have created, not too useful currently
 				namespaces = appendUnique(namespaces, ns)
+				if err = clusterloaderframework.RunHooks(p.Hooks.PostCreate, ns); err != nil {
+					failProject("Error running post-create hooks, %v", err)
+				}
+				if len(p.Hooks.PreDelete) > 0 {
+					preDeleteHooks = append(preDeleteHooks, namespaceHooks{ns: ns, hooks: p.Hooks.PreDelete})
+				}
 
 				// Create templates as defined
 				for _, template := range p.Templates {
-					if err = createTemplate(template.Basename, ns, clusterloaderframework.MakePath(template.File), template.Number, tuning); err != nil {
-						framework.Failf("Error creating template, %v", err)
+					endObject := timer.Start(clusterloaderframework.ObjectSpan, fmt.Sprintf("templates %v in %v", template.Basename, ns.Name))
+					if err = createTemplate(template.Basename, ns, clusterloaderframework.MakePath(template.File), template.Count(), tuning, arch.Primary); err != nil {
+						failProject("Error creating template, %v", err)
 					}
+					endObject()
 				}
 				// RCs are a thing as well
 				for _, RC := range p.RCs {
 					config, err := RC.ParseConfig()
 					if err != nil {
-						framework.Failf("Error parsing config, %v", err)
+						failProject("Error parsing config, %v", err)
 					}
+					arch.RewritePodSpec(&config.Spec)
 					label, err := RC.ConvertToLabelSet()
 					if err != nil {
-						framework.Failf("Error creating Labels, %v", err)
+						failProject("Error creating Labels, %v", err)
 					}
-					if err = clusterloaderframework.CreateRC(f, RC.Basename, ns.Name, label, config.Spec, RC.Number); err != nil {
-						framework.Failf("Error creating RC, %v", err)
+					endObject := timer.Start(clusterloaderframework.ObjectSpan, fmt.Sprintf("rc %v in %v", RC.Basename, ns.Name))
+					if err = clusterloaderframework.CreateRC(f, RC.Basename, ns.Name, label, config.Spec, RC.Count()); err != nil {
+						failProject("Error creating RC, %v", err)
 					}
+					endObject()
 				}
 				// This is too familiar, create pods
 				for _, pod := range p.Pods {
 					config, err := pod.ParseConfig()
 					if err != nil {
-						framework.Failf("Error parsing config, %v", err)
+						failProject("Error parsing config, %v", err)
 					}
+					arch.RewritePodSpec(&config.Spec)
 					label, err := pod.ConvertToLabelSet()
 					if err != nil {
-						framework.Failf("Error creating Labels, %v", err)
+						failProject("Error creating Labels, %v", err)
 					}
-					clusterloaderframework.CreatePods(f, pod.Basename, ns.Name, label, config.Spec, pod.Number, tuning)
+					endObject := timer.Start(clusterloaderframework.ObjectSpan, fmt.Sprintf("pods %v in %v", pod.Basename, ns.Name))
+					if err = clusterloaderframework.CreatePods(f, pod.Basename, ns.Name, label, config.Spec, pod.Count(), tuning); err != nil {
+						failProject("Error creating pods, %v", err)
+					}
+					endObject()
 				}
+				endNamespace()
+			}
+			if err := clusterloaderframework.RunProjectExec(p.Exec.After, p.Basename); err != nil {
+				failProject("Error running project exec: %v", err)
 			}
 			// Only sleeps for each new project defined in the config
 			// need to move up to sleep for every copy
@@ -106,22 +300,42 @@ var _ = framework.KubeDescribe("Cluster Loader [Feature:ManualPerformance]", fun
 			if tuning != nil {
 				tuning.Project.Delay()
 			}
+			endProject()
 		}
 
 		// Wait for pods to be running in all new namespaces
+		endWait := timer.Start(clusterloaderframework.PhaseSpan, "wait for pods")
 		for _, ns := range namespaces {
 			// TODO If created namespace doesn't have a pod with matching label we will hang
 			label := labels.SelectorFromSet(labels.Set(map[string]string{"purpose": "test"}))
 			err := testutils.WaitForPodsWithLabelRunning(c, ns.Name, label)
 			if err != nil {
+				clusterloaderframework.DumpClusterState(c, "wait", namespaces)
 				framework.Failf("Got %v when trying to wait for the pods to start", err)
 			}
 			gomega.Expect(err).NotTo(gomega.HaveOccurred())
 			framework.Logf("All pods running in namespace %s.", ns.Name)
 		}
+		endWait()
+
+		// Gather measurements once everything is running
+		endMeasurements := timer.Start(clusterloaderframework.PhaseSpan, "gather measurements")
+		if err := gatherMeasurements(); err != nil {
+			framework.Failf("Error gathering measurements: %v", err)
+		}
+		endMeasurements()
+		if err := clusterloaderframework.PushSummaries(c, clusterloaderframework.ConfigContext.ClusterLoader.Pushgateway, summaries); err != nil {
+			framework.Logf("Error pushing summaries to the Pushgateway: %v", err)
+		}
 	})
 })
 
+// namespaceHooks are hooks waiting to be run for a namespace
+type namespaceHooks struct {
+	ns    *v1.Namespace
+	hooks []clusterloaderframework.Hook
+}
+
 // appendUnique appends new namespace pointers to a slice
 func appendUnique(allNS []*v1.Namespace, newNS *v1.Namespace) []*v1.Namespace {
 	for _, NS := range allNS {
@@ -132,35 +346,33 @@ func appendUnique(allNS []*v1.Namespace, newNS *v1.Namespace) []*v1.Namespace {
 	return append(allNS, newNS)
 }
 
+var (
+	// ${IDENTIFER} is what we're replacing in the file
+	identifierRegex = regexp.MustCompile("\\${IDENTIFIER}")
+	// ${ARCH} is the architecture of the nodes
+	archRegex = regexp.MustCompile("\\${ARCH}")
+)
+
 // createTemplate does regex substitution against the template file, then creates the template
-func createTemplate(baseName string, ns *v1.Namespace, configPath string, numObjects int, tuning *clusterloaderframework.TuningSet) error {
-	// Try to read the file
-	content, err := ioutil.ReadFile(configPath)
+func createTemplate(baseName string, ns *v1.Namespace, configPath string, numObjects int, tuning *clusterloaderframework.TuningSet, arch string) error {
+	// Try to read the file, it is read only once for all namespaces
+	content, err := clusterloaderframework.ReadContent(configPath)
 	if err != nil {
 		return err
 	}
 
-	// ${IDENTIFER} is what we're replacing in the file
-	regex := regexp.MustCompile("\\${IDENTIFIER}")
+	content = archRegex.ReplaceAll(content, []byte(arch))
 
 	for i := 0; i < numObjects; i++ {
-		result := regex.ReplaceAll(content, []byte(strconv.Itoa(i)))
+		result := identifierRegex.ReplaceAll(content, []byte(strconv.Itoa(i)))
 
-		tmpfile, err := ioutil.TempFile("", "cl")
-		if err != nil {
+		// The copy is passed on stdin instead of writing a file for every object
+		args := append([]string{"create", "-f", "-", getNsCmdFlag(ns)}, clusterloaderframework.KubectlIdentityArgs()...)
+		if _, err := framework.NewKubectlCommand(args...).WithStdinData(string(result)).Exec(); err != nil {
 			return err
 		}
-		defer os.Remove(tmpfile.Name())
-
-		if _, err := tmpfile.Write(result); err != nil {
-			return err
-		}
-		if err := tmpfile.Close(); err != nil {
-			return err
-		}
-
-		framework.RunKubectlOrDie("create", "-f", tmpfile.Name(), getNsCmdFlag(ns))
 		framework.Logf("%d/%d : Created template %s", i+1, numObjects, baseName)
+		clusterloaderframework.ObjectsCreated(1)
 
 		// If there is a tuning set defined for this template
 		if tuning != nil {
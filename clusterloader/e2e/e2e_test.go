@@ -19,6 +19,7 @@ package clusterloader
 import (
 	"testing"
 
+	"github.com/golang/glog"
 	"github.com/spf13/viper"
 	"k8s.io/kubernetes/test/e2e/framework"
 	_ "k8s.io/perf-tests/clusterloader"
@@ -26,8 +27,13 @@ import (
 )
 
 func init() {
+	clframe.RegisterFlags()
+	clframe.RegisterSelfMetrics()
 	framework.ViperizeFlags()
-	clframe.ParseConfig(framework.TestContext.Viper)
+	clframe.DefaultKubeconfig()
+	if err := clframe.ParseConfig(framework.TestContext.Viper); err != nil {
+		glog.Fatal(err)
+	}
 }
 
 func TestE2E(t *testing.T) {
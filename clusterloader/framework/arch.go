@@ -0,0 +1,118 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"regexp"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/api/v1"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+// archSuffix matches the architecture suffix of image names like k8s.gcr.io/pause-amd64:3.0
+var archSuffix = regexp.MustCompile(`-(amd64|arm64|arm|ppc64le|s390x)([:@]|$)`)
+
+// osLabel is the node label with the operating system of the node
+const osLabel = "beta.kubernetes.io/os"
+
+// ClusterArch describes the CPU architectures of the schedulable nodes
+type ClusterArch struct {
+	// Primary is the architecture most of the Linux nodes have
+	Primary string
+	// Mixed is set when the Linux nodes don't all have the same architecture
+	Mixed bool
+	// WindowsNodes is the number of schedulable Windows nodes, the pods of the test are kept off them
+	WindowsNodes int
+}
+
+// DetectArch reads the architectures and operating systems reported by the schedulable nodes
+func DetectArch(c clientset.Interface) (ClusterArch, error) {
+	nodes, err := c.Core().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return ClusterArch{}, err
+	}
+	counts := make(map[string]int)
+	windows := 0
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		switch {
+		case node.Spec.Unschedulable:
+		case isWindowsNode(node):
+			windows++
+		case node.Status.NodeInfo.Architecture != "":
+			counts[node.Status.NodeInfo.Architecture]++
+		}
+	}
+	arch := ClusterArch{Mixed: len(counts) > 1, WindowsNodes: windows}
+	for name, count := range counts {
+		if count > counts[arch.Primary] || (count == counts[arch.Primary] && name < arch.Primary) {
+			arch.Primary = name
+		}
+	}
+	framework.Logf("Node architectures: %v, Windows nodes: %d", counts, windows)
+	return arch, nil
+}
+
+// isWindowsNode checks the operating system reported by the kubelet, or the label of the node
+// when the kubelet didn't report it yet
+func isWindowsNode(node *v1.Node) bool {
+	if node.Status.NodeInfo.OperatingSystem != "" {
+		return node.Status.NodeInfo.OperatingSystem == "windows"
+	}
+	return node.Labels[osLabel] == "windows"
+}
+
+// RewriteImage replaces the architecture suffix of the image with the architecture of the nodes.
+// In mixed clusters the suffix is dropped instead so the multi-arch manifest of the image is used.
+func (a ClusterArch) RewriteImage(image string) string {
+	if a.Primary == "" {
+		return image
+	}
+	replacement := "-" + a.Primary + "$2"
+	if a.Mixed {
+		replacement = "$2"
+	}
+	rewritten := archSuffix.ReplaceAllString(image, replacement)
+	if rewritten != image {
+		framework.Logf("Rewrote image %v to %v", image, rewritten)
+	}
+	return rewritten
+}
+
+// RewritePodSpec rewrites the images of all containers of the spec. In clusters with Windows nodes
+// the pod is also restricted to Linux nodes, unless the spec already selects an operating system.
+func (a ClusterArch) RewritePodSpec(spec *v1.PodSpec) {
+	a.selectLinux(spec)
+	for i := range spec.InitContainers {
+		spec.InitContainers[i].Image = a.RewriteImage(spec.InitContainers[i].Image)
+	}
+	for i := range spec.Containers {
+		spec.Containers[i].Image = a.RewriteImage(spec.Containers[i].Image)
+	}
+}
+
+// selectLinux adds the node selector keeping the pod off Windows nodes, if there are any
+func (a ClusterArch) selectLinux(spec *v1.PodSpec) {
+	if a.WindowsNodes > 0 && spec.NodeSelector[osLabel] == "" {
+		if spec.NodeSelector == nil {
+			spec.NodeSelector = make(map[string]string)
+		}
+		spec.NodeSelector[osLabel] = "linux"
+	}
+}
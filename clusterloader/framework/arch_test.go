@@ -0,0 +1,74 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api/v1"
+)
+
+func TestRewriteImage(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		arch     ClusterArch
+		image    string
+		expected string
+	}{
+		{"unknown arch", ClusterArch{}, "k8s.gcr.io/pause-amd64:3.0", "k8s.gcr.io/pause-amd64:3.0"},
+		{"same arch", ClusterArch{Primary: "amd64"}, "k8s.gcr.io/pause-amd64:3.0", "k8s.gcr.io/pause-amd64:3.0"},
+		{"other arch", ClusterArch{Primary: "arm64"}, "k8s.gcr.io/pause-amd64:3.0", "k8s.gcr.io/pause-arm64:3.0"},
+		{"arm suffix", ClusterArch{Primary: "ppc64le"}, "k8s.gcr.io/pause-arm:3.0", "k8s.gcr.io/pause-ppc64le:3.0"},
+		{"without tag", ClusterArch{Primary: "arm64"}, "k8s.gcr.io/pause-amd64", "k8s.gcr.io/pause-arm64"},
+		{"digest", ClusterArch{Primary: "s390x"}, "k8s.gcr.io/pause-amd64@sha256:abc", "k8s.gcr.io/pause-s390x@sha256:abc"},
+		{"mixed", ClusterArch{Primary: "amd64", Mixed: true}, "k8s.gcr.io/pause-amd64:3.0", "k8s.gcr.io/pause:3.0"},
+		{"no suffix", ClusterArch{Primary: "arm64"}, "k8s.gcr.io/busybox:1.24", "k8s.gcr.io/busybox:1.24"},
+		{"suffix in the middle", ClusterArch{Primary: "arm64"}, "k8s.gcr.io/amd64-tools/busybox:1.24", "k8s.gcr.io/amd64-tools/busybox:1.24"},
+	} {
+		if image := test.arch.RewriteImage(test.image); image != test.expected {
+			t.Errorf("%v: RewriteImage(%q) = %q, expected %q", test.name, test.image, image, test.expected)
+		}
+	}
+}
+
+func TestRewritePodSpec(t *testing.T) {
+	for _, test := range []struct {
+		name         string
+		arch         ClusterArch
+		nodeSelector map[string]string
+		expected     map[string]string
+	}{
+		{"linux only", ClusterArch{Primary: "arm64"}, nil, nil},
+		{"windows nodes", ClusterArch{Primary: "arm64", WindowsNodes: 2}, nil, map[string]string{osLabel: "linux"}},
+		{"other selector", ClusterArch{Primary: "arm64", WindowsNodes: 2}, map[string]string{"pool": "a"}, map[string]string{"pool": "a", osLabel: "linux"}},
+		{"selects windows", ClusterArch{Primary: "arm64", WindowsNodes: 2}, map[string]string{osLabel: "windows"}, map[string]string{osLabel: "windows"}},
+	} {
+		spec := v1.PodSpec{
+			NodeSelector:   test.nodeSelector,
+			InitContainers: []v1.Container{{Image: "k8s.gcr.io/busybox-amd64:1.24"}},
+			Containers:     []v1.Container{{Image: "k8s.gcr.io/pause-amd64:3.0"}},
+		}
+		test.arch.RewritePodSpec(&spec)
+		if !reflect.DeepEqual(spec.NodeSelector, test.expected) {
+			t.Errorf("%v: expected node selector %v, got %v", test.name, test.expected, spec.NodeSelector)
+		}
+		if spec.InitContainers[0].Image != "k8s.gcr.io/busybox-arm64:1.24" || spec.Containers[0].Image != "k8s.gcr.io/pause-arm64:3.0" {
+			t.Errorf("%v: expected the images to be rewritten to arm64, got %v and %v", test.name, spec.InitContainers[0].Image, spec.Containers[0].Image)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api/v1"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+const (
+	defaultAuditTopCalls = 20
+	defaultAuditHotQPS   = 10
+	// auditMaxLine is the longest audit log line parsed, longer lines are skipped and counted,
+	// events at the RequestResponse level of large LIST calls can be even larger
+	auditMaxLine = 16 * 1024 * 1024
+)
+
+// auditLegacyField matches the key="value" pairs of the legacy audit log format
+var auditLegacyField = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// auditLogMeasurement reads the apiserver audit log after the test and counts the calls made during it
+// by caller, verb and resource, callers above the hot QPS are reported separately
+type auditLogMeasurement struct {
+	path   string
+	top    int
+	hotQPS float64
+	start  time.Time
+}
+
+// auditEvent holds the fields used from the JSON audit events, the legacy text format is converted into it
+type auditEvent struct {
+	Stage     string    `json:"stage"`
+	Timestamp time.Time `json:"timestamp"`
+	// StageTimestamp replaced Timestamp in audit.k8s.io/v1beta1
+	StageTimestamp time.Time `json:"stageTimestamp"`
+	Verb           string    `json:"verb"`
+	RequestURI     string    `json:"requestURI"`
+	UserAgent      string    `json:"userAgent"`
+	User           struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	ObjectRef *struct {
+		Resource    string `json:"resource"`
+		Subresource string `json:"subresource"`
+	} `json:"objectRef"`
+}
+
+func newAuditLogMeasurement(config MeasurementConfig) (*auditLogMeasurement, error) {
+	m := &auditLogMeasurement{
+		path:   config.Params["path"],
+		top:    defaultAuditTopCalls,
+		hotQPS: defaultAuditHotQPS,
+	}
+	if m.path == "" {
+		return nil, fmt.Errorf("path param is required")
+	}
+	if top, ok := config.Params["top"]; ok {
+		value, err := strconv.Atoi(top)
+		if err != nil {
+			return nil, err
+		}
+		m.top = value
+	}
+	if hotQPS, ok := config.Params["hotqps"]; ok {
+		value, err := strconv.ParseFloat(hotQPS, 64)
+		if err != nil {
+			return nil, err
+		}
+		m.hotQPS = value
+	}
+	return m, nil
+}
+
+// Start only records the beginning of the test window
+func (m *auditLogMeasurement) Start(_ clientset.Interface) error {
+	m.start = time.Now()
+	return nil
+}
+
+// Stop reads the audit log and counts the calls between Start and Stop, in all namespaces
+func (m *auditLogMeasurement) Stop(_ []*v1.Namespace) (framework.TestDataSummary, error) {
+	end := time.Now()
+	file, err := os.Open(m.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	calls := make(map[AuditCallCount]int)
+	callers := make(map[string]int)
+	summary := &AuditLogSummary{Window: end.Sub(m.start)}
+	reader := bufio.NewReader(file)
+	for {
+		line, tooLong, err := readAuditLine(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading audit log %v: %v", m.path, err)
+		}
+		if tooLong {
+			summary.SkippedLines++
+			continue
+		}
+		event, ok := parseAuditLine(string(line))
+		if !ok {
+			continue
+		}
+		timestamp := event.StageTimestamp
+		if timestamp.IsZero() {
+			timestamp = event.Timestamp
+		}
+		if timestamp.Before(m.start) || timestamp.After(end) {
+			continue
+		}
+		caller := event.UserAgent
+		if caller == "" {
+			caller = event.User.Username
+		}
+		resource := resourceFromPath(strings.SplitN(event.RequestURI, "?", 2)[0])
+		if event.ObjectRef != nil && event.ObjectRef.Resource != "" {
+			resource = event.ObjectRef.Resource
+			if event.ObjectRef.Subresource != "" {
+				resource += "/" + event.ObjectRef.Subresource
+			}
+		}
+		summary.Calls++
+		calls[AuditCallCount{Caller: caller, Verb: event.Verb, Resource: resource}]++
+		callers[caller]++
+	}
+
+	seconds := summary.Window.Seconds()
+	for call, count := range calls {
+		call.Count = count
+		call.QPS = float64(count) / seconds
+		summary.TopCalls = append(summary.TopCalls, call)
+	}
+	sort.Sort(byAuditCount(summary.TopCalls))
+	if m.top > 0 && len(summary.TopCalls) > m.top {
+		summary.TopCalls = summary.TopCalls[:m.top]
+	}
+	for caller, count := range callers {
+		if qps := float64(count) / seconds; qps > m.hotQPS {
+			summary.HotCallers = append(summary.HotCallers, AuditCallCount{Caller: caller, Count: count, QPS: qps})
+		}
+	}
+	sort.Sort(byAuditCount(summary.HotCallers))
+	return summary, nil
+}
+
+// readAuditLine returns the next line of the audit log, the rest of lines longer than auditMaxLine is
+// discarded without buffering it and tooLong is set. io.EOF is only returned when no line is left.
+func readAuditLine(reader *bufio.Reader) (line []byte, tooLong bool, err error) {
+	for {
+		fragment, isPrefix, err := reader.ReadLine()
+		if err != nil {
+			if err == io.EOF && (len(line) > 0 || tooLong) {
+				return line, tooLong, nil
+			}
+			return nil, false, err
+		}
+		if !tooLong {
+			if len(line)+len(fragment) > auditMaxLine {
+				line, tooLong = nil, true
+			} else {
+				line = append(line, fragment...)
+			}
+		}
+		if !isPrefix {
+			return line, tooLong, nil
+		}
+	}
+}
+
+// parseAuditLine reads a JSON audit event, only the ResponseComplete stage is counted, or a line
+// of the legacy format, where only the request lines are counted
+func parseAuditLine(line string) (auditEvent, bool) {
+	var event auditEvent
+	if strings.HasPrefix(line, "{") {
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			return event, false
+		}
+		return event, event.Stage == "" || event.Stage == "ResponseComplete"
+	}
+	fields := strings.SplitN(line, " ", 2)
+	if len(fields) != 2 || !strings.HasPrefix(fields[1], "AUDIT:") {
+		return event, false
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, fields[0])
+	if err != nil {
+		return event, false
+	}
+	event.Timestamp = timestamp
+	for _, match := range auditLegacyField.FindAllStringSubmatch(fields[1], -1) {
+		switch match[1] {
+		case "method":
+			event.Verb = strings.ToLower(match[2])
+		case "user":
+			event.User.Username = match[2]
+		case "uri":
+			event.RequestURI = match[2]
+		}
+	}
+	return event, event.Verb != ""
+}
+
+// AuditLogSummary holds the calls found in the audit log during the test
+type AuditLogSummary struct {
+	Window time.Duration `json:"window"`
+	Calls  int           `json:"calls"`
+	// SkippedLines are the lines longer than the parsed limit, their calls aren't counted
+	SkippedLines int `json:"skippedLines"`
+	// TopCalls are the most frequent combinations of caller, verb and resource
+	TopCalls []AuditCallCount `json:"topCalls"`
+	// HotCallers are the callers whose calls exceeded the hot QPS across all verbs and resources
+	HotCallers []AuditCallCount `json:"hotCallers"`
+}
+
+// AuditCallCount is the number of calls of a caller, the user agent or the user for the legacy
+// format, with the verb to the resource
+type AuditCallCount struct {
+	Caller   string  `json:"caller"`
+	Verb     string  `json:"verb,omitempty"`
+	Resource string  `json:"resource,omitempty"`
+	Count    int     `json:"count"`
+	QPS      float64 `json:"qps"`
+}
+
+type byAuditCount []AuditCallCount
+
+func (a byAuditCount) Len() int      { return len(a) }
+func (a byAuditCount) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byAuditCount) Less(i, j int) bool {
+	if a[i].Count != a[j].Count {
+		return a[i].Count > a[j].Count
+	}
+	return fmt.Sprint(a[i]) < fmt.Sprint(a[j])
+}
+
+// SummaryKind returns the name of the summary
+func (s *AuditLogSummary) SummaryKind() string {
+	return "AuditLog"
+}
+
+// PrintHumanReadable prints the summary as a table
+func (s *AuditLogSummary) PrintHumanReadable() string {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 1, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "%v calls in %v, %v lines too long to parse\n", s.Calls, s.Window, s.SkippedLines)
+	fmt.Fprintf(w, "Caller\tVerb\tResource\tCount\tQPS\n")
+	for _, call := range s.TopCalls {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%.2f\n", call.Caller, call.Verb, call.Resource, call.Count, call.QPS)
+	}
+	for _, caller := range s.HotCallers {
+		fmt.Fprintf(w, "Hot caller: %v with %v calls, %.2f QPS\n", caller.Caller, caller.Count, caller.QPS)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// PrintJSON prints the summary as json
+func (s *AuditLogSummary) PrintJSON() string {
+	return framework.PrettyPrintJSON(s)
+}
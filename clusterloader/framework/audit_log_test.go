@@ -0,0 +1,136 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAuditLine(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		line      string
+		ok        bool
+		verb      string
+		user      string
+		userAgent string
+		uri       string
+		resource  string
+		timestamp string
+	}{
+		{
+			name:      "json v1beta1",
+			line:      `{"kind":"Event","apiVersion":"audit.k8s.io/v1beta1","stage":"ResponseComplete","requestURI":"/api/v1/namespaces/ns/pods/pod-1/status","verb":"update","user":{"username":"system:node:node-1"},"userAgent":"kubelet/v1.8.0","objectRef":{"resource":"pods","subresource":"status","namespace":"ns","name":"pod-1"},"stageTimestamp":"2017-09-01T12:00:01.5Z"}`,
+			ok:        true,
+			verb:      "update",
+			user:      "system:node:node-1",
+			userAgent: "kubelet/v1.8.0",
+			uri:       "/api/v1/namespaces/ns/pods/pod-1/status",
+			resource:  "pods",
+			timestamp: "2017-09-01T12:00:01.5Z",
+		},
+		{
+			name:      "json v1alpha1",
+			line:      `{"kind":"Event","apiVersion":"audit.k8s.io/v1alpha1","requestURI":"/api/v1/nodes","verb":"list","user":{"username":"admin"},"objectRef":{"resource":"nodes"},"timestamp":"2017-09-01T12:00:00Z"}`,
+			ok:        true,
+			verb:      "list",
+			user:      "admin",
+			uri:       "/api/v1/nodes",
+			resource:  "nodes",
+			timestamp: "2017-09-01T12:00:00Z",
+		},
+		{
+			name: "json other stage",
+			line: `{"kind":"Event","stage":"RequestReceived","verb":"list","requestURI":"/api/v1/nodes"}`,
+		},
+		{
+			name: "invalid json",
+			line: `{"kind":"Event",`,
+		},
+		{
+			name:      "legacy request",
+			line:      `2017-09-01T12:00:00.123456789Z AUDIT: id="c939d2a7" ip="127.0.0.1" method="GET" user="admin" groups="\"system:masters\"" as="<self>" asgroups="<lookup>" namespace="ns" uri="/api/v1/namespaces/ns/pods?watch=true"`,
+			ok:        true,
+			verb:      "get",
+			user:      "admin",
+			uri:       "/api/v1/namespaces/ns/pods?watch=true",
+			timestamp: "2017-09-01T12:00:00.123456789Z",
+		},
+		{
+			name: "legacy response",
+			line: `2017-09-01T12:00:00.223456789Z AUDIT: id="c939d2a7" response="200"`,
+		},
+		{
+			name: "other log line",
+			line: `I0901 12:00:00.000000       1 handlers.go:50] GET /api/v1/nodes: (1ms) 200`,
+		},
+	} {
+		event, ok := parseAuditLine(test.line)
+		if ok != test.ok {
+			t.Errorf("%v: expected ok %v, got %v", test.name, test.ok, ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if event.Verb != test.verb || event.User.Username != test.user || event.UserAgent != test.userAgent || event.RequestURI != test.uri {
+			t.Errorf("%v: unexpected event %+v", test.name, event)
+		}
+		resource := ""
+		if event.ObjectRef != nil {
+			resource = event.ObjectRef.Resource
+		}
+		if resource != test.resource {
+			t.Errorf("%v: expected resource %q, got %q", test.name, test.resource, resource)
+		}
+		timestamp := event.StageTimestamp
+		if timestamp.IsZero() {
+			timestamp = event.Timestamp
+		}
+		if expected, _ := time.Parse(time.RFC3339Nano, test.timestamp); !timestamp.Equal(expected) {
+			t.Errorf("%v: expected timestamp %v, got %v", test.name, expected, timestamp)
+		}
+	}
+}
+
+func TestReadAuditLine(t *testing.T) {
+	long := strings.Repeat("x", auditMaxLine+1)
+	reader := bufio.NewReader(strings.NewReader("first\n" + long + "\nlast"))
+	for _, expected := range []struct {
+		line    string
+		tooLong bool
+	}{
+		{"first", false},
+		{"", true},
+		{"last", false},
+	} {
+		line, tooLong, err := readAuditLine(reader)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(line) != expected.line || tooLong != expected.tooLong {
+			t.Errorf("expected %q (too long %v), got %.10q (too long %v)", expected.line, expected.tooLong, line, tooLong)
+		}
+	}
+	if _, _, err := readAuditLine(reader); err != io.EOF {
+		t.Errorf("expected io.EOF after the last line, got %v", err)
+	}
+}
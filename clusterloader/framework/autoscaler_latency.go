@@ -0,0 +1,287 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kubernetes/pkg/api/v1"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	"k8s.io/kubernetes/test/e2e/framework"
+	"k8s.io/kubernetes/test/e2e/perftype"
+)
+
+// autoscalerLatencyMeasurement tracks pods that were unschedulable when created and reports
+// how long the cluster autoscaler needed to provision nodes for them and get them scheduled.
+// It creates pods requesting more than the nodes have free in a namespace of its own, or only
+// observes the pods of the projects when no pods are configured.
+type autoscalerLatencyMeasurement struct {
+	c        clientset.Interface
+	pods     int
+	requests v1.ResourceList
+	// ns is the namespace of the created pods, it is deleted in Stop
+	ns      *v1.Namespace
+	lock    sync.Mutex
+	pending map[string]*pendingPod
+	stopCh  chan struct{}
+}
+
+func newAutoscalerLatencyMeasurement(config MeasurementConfig) (*autoscalerLatencyMeasurement, error) {
+	m := &autoscalerLatencyMeasurement{requests: v1.ResourceList{}}
+	if pods, ok := config.Params["pods"]; ok {
+		value, err := strconv.Atoi(pods)
+		if err != nil {
+			return nil, err
+		}
+		if value < 0 {
+			return nil, fmt.Errorf("pods can't be negative, got %d", value)
+		}
+		m.pods = value
+	}
+	cpu := "1"
+	if value, ok := config.Params["cpu"]; ok {
+		cpu = value
+	}
+	for name, value := range map[v1.ResourceName]string{v1.ResourceCPU: cpu, v1.ResourceMemory: config.Params["memory"]} {
+		if value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %v request %q: %v", name, value, err)
+		}
+		m.requests[name] = quantity
+	}
+	return m, nil
+}
+
+type pendingPod struct {
+	namespace string
+	name      string
+	created   time.Time
+	scheduled time.Time
+	node      string
+}
+
+// Start watches pods in all namespaces and creates the configured pods
+func (m *autoscalerLatencyMeasurement) Start(c clientset.Interface) error {
+	m.c = c
+	m.pending = make(map[string]*pendingPod)
+	m.stopCh = startInformer(&cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return c.Core().Pods(metav1.NamespaceAll).List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return c.Core().Pods(metav1.NamespaceAll).Watch(options)
+		},
+	}, &v1.Pod{}, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			m.update(obj.(*v1.Pod))
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			m.update(obj.(*v1.Pod))
+		},
+	})
+	if m.pods == 0 {
+		return nil
+	}
+	return m.createPods()
+}
+
+// createPods creates pause pods with the configured requests, which don't fit the free capacity
+// of the nodes once enough of them are created and trigger a scale-up
+func (m *autoscalerLatencyMeasurement) createPods() error {
+	arch, err := DetectArch(m.c)
+	if err != nil {
+		return err
+	}
+	if m.ns, err = createTestingNS("autoscaler", m.c, nil); err != nil {
+		return err
+	}
+	zero := int64(0)
+	spec := v1.PodSpec{
+		TerminationGracePeriodSeconds: &zero,
+		Containers: []v1.Container{
+			{
+				Name:      "autoscaler",
+				Image:     framework.GetPauseImageName(m.c),
+				Resources: v1.ResourceRequirements{Requests: m.requests},
+			},
+		},
+	}
+	arch.RewritePodSpec(&spec)
+	framework.Logf("Creating %d pods requesting %v in %v to trigger a scale-up", m.pods, m.requests, m.ns.Name)
+	for i := 0; i < m.pods; i++ {
+		pod := newPod("autoscaler", m.ns.Name, i, map[string]string{"purpose": "autoscaler"}, spec)
+		err := retryWithBackoff(fmt.Sprintf("creating pod %v/%v", m.ns.Name, pod.Name), func() error {
+			_, err := m.c.Core().Pods(m.ns.Name).Create(pod)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *autoscalerLatencyMeasurement) update(pod *v1.Pod) {
+	_, condition := podutil.GetPodCondition(&pod.Status, v1.PodScheduled)
+	if condition == nil {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	key := pod.Namespace + "/" + pod.Name
+	p, ok := m.pending[key]
+	if !ok {
+		if condition.Status != v1.ConditionFalse || condition.Reason != v1.PodReasonUnschedulable {
+			return
+		}
+		p = &pendingPod{namespace: pod.Namespace, name: pod.Name, created: pod.CreationTimestamp.Time}
+		m.pending[key] = p
+	}
+	if p.scheduled.IsZero() && condition.Status == v1.ConditionTrue {
+		p.scheduled = condition.LastTransitionTime.Time
+		p.node = pod.Spec.NodeName
+	}
+}
+
+// Stop summarizes the latencies of the unschedulable pods from the namespaces and the created pods,
+// the namespace of the created pods is deleted
+func (m *autoscalerLatencyMeasurement) Stop(namespaces []*v1.Namespace) (framework.TestDataSummary, error) {
+	close(m.stopCh)
+	if m.ns != nil {
+		namespaces = append(namespaces, m.ns)
+		defer func() {
+			if err := m.c.Core().Namespaces().Delete(m.ns.Name, nil); err != nil {
+				framework.Logf("Error deleting namespace %v: %v", m.ns.Name, err)
+			}
+		}()
+	}
+	nodes, err := m.c.Core().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	nodeCreated := make(map[string]time.Time)
+	nodeReady := make(map[string]time.Time)
+	for _, node := range nodes.Items {
+		nodeCreated[node.Name] = node.CreationTimestamp.Time
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == v1.NodeReady && condition.Status == v1.ConditionTrue {
+				nodeReady[node.Name] = condition.LastTransitionTime.Time
+			}
+		}
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	nsSet := namespaceSet(namespaces)
+	summary := &AutoscalerLatencySummary{}
+	var toScheduled, toNodeCreated, toNodeReady []framework.PodLatencyData
+	newNodes := make(map[string]bool)
+	for _, p := range m.pending {
+		if !nsSet[p.namespace] {
+			continue
+		}
+		summary.UnschedulablePods++
+		if p.scheduled.IsZero() {
+			summary.StillPendingPods++
+			continue
+		}
+		toScheduled = append(toScheduled, framework.PodLatencyData{Name: p.name, Node: p.node, Latency: p.scheduled.Sub(p.created)})
+		created, ok := nodeCreated[p.node]
+		if !ok || created.Before(p.created) {
+			// The pod landed on a node that existed before it, e.g. after other pods were removed
+			continue
+		}
+		toNodeCreated = append(toNodeCreated, framework.PodLatencyData{Name: p.name, Node: p.node, Latency: created.Sub(p.created)})
+		if !newNodes[p.node] {
+			newNodes[p.node] = true
+			if ready, ok := nodeReady[p.node]; ok {
+				toNodeReady = append(toNodeReady, framework.PodLatencyData{Name: p.node, Node: p.node, Latency: ready.Sub(created)})
+			}
+		}
+	}
+	summary.NewNodes = len(newNodes)
+	summary.PendingToScheduled = latencyMetric(toScheduled)
+	summary.PendingToNodeCreated = latencyMetric(toNodeCreated)
+	summary.NodeCreatedToReady = latencyMetric(toNodeReady)
+	return summary, nil
+}
+
+// AutoscalerLatencySummary holds the scale-up latencies observed for unschedulable pods
+type AutoscalerLatencySummary struct {
+	UnschedulablePods    int                     `json:"unschedulablePods"`
+	StillPendingPods     int                     `json:"stillPendingPods"`
+	NewNodes             int                     `json:"newNodes"`
+	PendingToNodeCreated framework.LatencyMetric `json:"pendingToNodeCreated"`
+	NodeCreatedToReady   framework.LatencyMetric `json:"nodeCreatedToReady"`
+	PendingToScheduled   framework.LatencyMetric `json:"pendingToScheduled"`
+}
+
+// SummaryKind returns the name of the summary
+func (s *AutoscalerLatencySummary) SummaryKind() string {
+	return "AutoscalerLatency"
+}
+
+// PrintHumanReadable prints the summary as a table
+func (s *AutoscalerLatencySummary) PrintHumanReadable() string {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 1, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "Unschedulable pods: %v, still pending: %v, new nodes: %v\n", s.UnschedulablePods, s.StillPendingPods, s.NewNodes)
+	fmt.Fprintf(w, "Latency\tPerc50\tPerc90\tPerc99\tPerc100\n")
+	for _, l := range []struct {
+		name   string
+		metric framework.LatencyMetric
+	}{
+		{"pending_to_node_created", s.PendingToNodeCreated},
+		{"node_created_to_ready", s.NodeCreatedToReady},
+		{"pending_to_scheduled", s.PendingToScheduled},
+	} {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", l.name, l.metric.Perc50, l.metric.Perc90, l.metric.Perc99, l.metric.Perc100)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// PrintJSON prints the summary as json
+func (s *AutoscalerLatencySummary) PrintJSON() string {
+	return framework.PrettyPrintJSON(s)
+}
+
+// PerfData converts the summary into perfdash data items, one per scale-up phase
+func (s *AutoscalerLatencySummary) PerfData() *perftype.PerfData {
+	return &perftype.PerfData{
+		Version: currentPerfDataVersion,
+		DataItems: []perftype.DataItem{
+			latencyToDataItem(s.PendingToNodeCreated, map[string]string{"Metric": "pending_to_node_created"}),
+			latencyToDataItem(s.NodeCreatedToReady, map[string]string{"Metric": "node_created_to_ready"}),
+			latencyToDataItem(s.PendingToScheduled, map[string]string{"Metric": "pending_to_scheduled"}),
+		},
+	}
+}
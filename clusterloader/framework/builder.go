@@ -0,0 +1,155 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+// ConfigBuilder constructs a Cluster Loader config from code instead of a viper config file.
+// The result of Build can be assigned to ConfigContext before the tests are run, from a test binary
+// of its own since the one of the e2e package requires a viper config file.
+type ConfigBuilder struct {
+	context Context
+}
+
+// NewConfigBuilder returns a builder of an empty config
+func NewConfigBuilder() *ConfigBuilder {
+	return &ConfigBuilder{}
+}
+
+// WithProject adds a project to the config
+func (b *ConfigBuilder) WithProject(project *ProjectBuilder) *ConfigBuilder {
+	b.context.ClusterLoader.Projects = append(b.context.ClusterLoader.Projects, project.project)
+	return b
+}
+
+// WithTuningSet adds a tuning set which can be referenced by projects
+func (b *ConfigBuilder) WithTuningSet(tuningSet TuningSet) *ConfigBuilder {
+	b.context.ClusterLoader.TuningSets = append(b.context.ClusterLoader.TuningSets, tuningSet)
+	return b
+}
+
+// WithMeasurement adds a measurement run alongside the projects
+func (b *ConfigBuilder) WithMeasurement(name string, params map[string]string) *ConfigBuilder {
+	b.context.ClusterLoader.Measurements = append(b.context.ClusterLoader.Measurements, MeasurementConfig{Name: name, Params: params})
+	return b
+}
+
+// WithWarmup sets the warmup run before the projects
+func (b *ConfigBuilder) WithWarmup(warmup WarmupConfig) *ConfigBuilder {
+	b.context.ClusterLoader.Warmup = warmup
+	return b
+}
+
+// WithChurn sets the background churn run while the projects are created
+func (b *ConfigBuilder) WithChurn(churn ChurnConfig) *ConfigBuilder {
+	b.context.ClusterLoader.Churn = churn
+	return b
+}
+
+// WithPreflight sets the checks of the target cluster run before anything is created
+func (b *ConfigBuilder) WithPreflight(preflight PreflightConfig) *ConfigBuilder {
+	b.context.ClusterLoader.Preflight = preflight
+	return b
+}
+
+// WithPushgateway sets the Prometheus Pushgateway the measurement results are pushed to
+func (b *ConfigBuilder) WithPushgateway(pushgateway PushgatewayConfig) *ConfigBuilder {
+	b.context.ClusterLoader.Pushgateway = pushgateway
+	return b
+}
+
+// WithTiming sets the timing report of the run
+func (b *ConfigBuilder) WithTiming(timing TimingConfig) *ConfigBuilder {
+	b.context.ClusterLoader.Timing = timing
+	return b
+}
+
+// WithGrafana sets the Grafana instance the phases of the run are annotated in
+func (b *ConfigBuilder) WithGrafana(grafana GrafanaConfig) *ConfigBuilder {
+	b.context.ClusterLoader.Grafana = grafana
+	return b
+}
+
+// Build validates and returns the config
+func (b *ConfigBuilder) Build() (Context, error) {
+	if err := b.context.Validate(); err != nil {
+		return Context{}, err
+	}
+	return b.context, nil
+}
+
+// ProjectBuilder constructs a single project of the config
+type ProjectBuilder struct {
+	project ClusterLoader
+}
+
+// NewProject returns a builder of a project creating num namespaces named after basename
+func NewProject(basename string, num int) *ProjectBuilder {
+	return &ProjectBuilder{project: ClusterLoader{Basename: basename, Number: num}}
+}
+
+// WithTuning sets the name of the tuning set used by the project
+func (b *ProjectBuilder) WithTuning(name string) *ProjectBuilder {
+	b.project.Tuning = name
+	return b
+}
+
+// WithPods adds pods created in every namespace of the project
+func (b *ProjectBuilder) WithPods(pods ...ClusterLoaderObject) *ProjectBuilder {
+	b.project.Pods = append(b.project.Pods, pods...)
+	return b
+}
+
+// WithRCs adds replication controllers created in every namespace of the project
+func (b *ProjectBuilder) WithRCs(rcs ...ClusterLoaderObject) *ProjectBuilder {
+	b.project.RCs = append(b.project.RCs, rcs...)
+	return b
+}
+
+// WithTemplates adds templates created in every namespace of the project
+func (b *ProjectBuilder) WithTemplates(templates ...ClusterLoaderObject) *ProjectBuilder {
+	b.project.Templates = append(b.project.Templates, templates...)
+	return b
+}
+
+// WithHooks sets the namespace hooks of the project
+func (b *ProjectBuilder) WithHooks(hooks NamespaceHooks) *ProjectBuilder {
+	b.project.Hooks = hooks
+	return b
+}
+
+// WithIdentities sets the identities impersonated when creating the pods, RCs and templates of the project
+func (b *ProjectBuilder) WithIdentities(identities ...Identity) *ProjectBuilder {
+	b.project.Identities = append(b.project.Identities, identities...)
+	return b
+}
+
+// WithNamespace sets the labels and annotations applied to the namespaces of the project
+func (b *ProjectBuilder) WithNamespace(namespace NamespaceConfig) *ProjectBuilder {
+	b.project.Namespace = namespace
+	return b
+}
+
+// WithExistingNamespaces makes the project run in the existing namespaces instead of creating new ones
+func (b *ProjectBuilder) WithExistingNamespaces(names ...string) *ProjectBuilder {
+	b.project.Namespaces = append(b.project.Namespaces, names...)
+	return b
+}
+
+// WithExec sets the commands run before and after the namespaces of the project are set up
+func (b *ProjectBuilder) WithExec(exec ProjectExec) *ProjectBuilder {
+	b.project.Exec = exec
+	return b
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/api/v1"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+const defaultChurnInterval = time.Second
+
+// Churner keeps replacing the pods of a small population in its own namespace in the background
+type Churner struct {
+	c        clientset.Interface
+	ns       string
+	spec     v1.PodSpec
+	interval time.Duration
+	// pods are the names of the live pods, oldest first
+	pods   []string
+	next   int
+	start  time.Time
+	stopCh chan struct{}
+	doneCh chan struct{}
+	// Only the churn goroutine updates the counts until it is done
+	summary ChurnSummary
+}
+
+// StartChurn creates the churned population and starts replacing its pods, it returns nil when
+// no churn is configured. The images of the pods are rewritten for the architecture of the nodes.
+func StartChurn(f *framework.Framework, churn ChurnConfig, arch ClusterArch) (*Churner, error) {
+	if churn.Pods == 0 {
+		return nil, nil
+	}
+	interval := defaultChurnInterval
+	if churn.Interval != "" {
+		var err error
+		if interval, err = time.ParseDuration(churn.Interval); err != nil {
+			return nil, err
+		}
+		if interval <= 0 {
+			return nil, fmt.Errorf("churn interval must be positive, got %v", interval)
+		}
+	}
+	c := f.ClientSet
+	ns, err := f.CreateNamespace("churn", nil)
+	if err != nil {
+		return nil, err
+	}
+	image := churn.Image
+	if image == "" {
+		image = framework.GetPauseImageName(c)
+	}
+	zero := int64(0)
+	ch := &Churner{
+		c:  c,
+		ns: ns.Name,
+		spec: v1.PodSpec{
+			TerminationGracePeriodSeconds: &zero,
+			Containers: []v1.Container{
+				{
+					Name:  "churn",
+					Image: image,
+				},
+			},
+		},
+		interval: interval,
+		start:    time.Now(),
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+	arch.RewritePodSpec(&ch.spec)
+	framework.Logf("Starting churn of %d pods in %v, replacing a pod every %v", churn.Pods, ns.Name, interval)
+	for i := 0; i < churn.Pods; i++ {
+		if err := ch.create(); err != nil {
+			return nil, err
+		}
+	}
+	go ch.run()
+	return ch, nil
+}
+
+func (ch *Churner) run() {
+	defer close(ch.doneCh)
+	ticker := time.NewTicker(ch.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ch.stopCh:
+			return
+		case <-ticker.C:
+			// Failures are counted but don't stop the churn, it is background load only
+			if err := ch.deleteOldest(); err != nil {
+				ch.summary.Errors++
+				framework.Logf("Churn: %v", err)
+			}
+			if err := ch.create(); err != nil {
+				ch.summary.Errors++
+				framework.Logf("Churn: %v", err)
+			}
+		}
+	}
+}
+
+func (ch *Churner) create() error {
+	pod := newPod("churn", ch.ns, ch.next, map[string]string{"purpose": "churn"}, ch.spec)
+	ch.next++
+	err := retryWithBackoff(fmt.Sprintf("creating pod %v/%v", ch.ns, pod.Name), func() error {
+		_, err := ch.c.Core().Pods(ch.ns).Create(pod)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("error creating pod %v: %v", pod.Name, err)
+	}
+	ch.pods = append(ch.pods, pod.Name)
+	ch.summary.Created++
+	return nil
+}
+
+func (ch *Churner) deleteOldest() error {
+	if len(ch.pods) == 0 {
+		return nil
+	}
+	name := ch.pods[0]
+	ch.pods = ch.pods[1:]
+	if err := ch.c.Core().Pods(ch.ns).Delete(name, metav1.NewDeleteOptions(0)); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("error deleting pod %v: %v", name, err)
+	}
+	ch.summary.Deleted++
+	return nil
+}
+
+// Stop stops the churn and summarizes it, the pods are deleted with the namespace
+func (ch *Churner) Stop() *ChurnSummary {
+	close(ch.stopCh)
+	<-ch.doneCh
+	summary := ch.summary
+	summary.Namespace = ch.ns
+	summary.Pods = len(ch.pods)
+	summary.Duration = time.Since(ch.start)
+	return &summary
+}
+
+// ChurnSummary holds the operations of the background churn
+type ChurnSummary struct {
+	Namespace string        `json:"namespace"`
+	Pods      int           `json:"pods"`
+	Created   int           `json:"created"`
+	Deleted   int           `json:"deleted"`
+	Errors    int           `json:"errors"`
+	Duration  time.Duration `json:"duration"`
+}
+
+// SummaryKind returns the name of the summary
+func (s *ChurnSummary) SummaryKind() string {
+	return "Churn"
+}
+
+// PrintHumanReadable prints the summary as a table
+func (s *ChurnSummary) PrintHumanReadable() string {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 1, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "Churn in %v for %v, %d pods left\n", s.Namespace, s.Duration, s.Pods)
+	fmt.Fprintf(w, "Created\tDeleted\tErrors\n")
+	fmt.Fprintf(w, "%d\t%d\t%d\n", s.Created, s.Deleted, s.Errors)
+	w.Flush()
+	return buf.String()
+}
+
+// PrintJSON prints the summary as json
+func (s *ChurnSummary) PrintJSON() string {
+	return framework.PrettyPrintJSON(s)
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+	"k8s.io/kubernetes/test/e2e/perftype"
+)
+
+type clientLatencyKey struct {
+	resource string
+	verb     string
+}
+
+var (
+	clientLatencyLock sync.Mutex
+	// clientLatencies are the latencies of the mutating requests observed by the clients
+	clientLatencies = make(map[clientLatencyKey][]framework.PodLatencyData)
+)
+
+// recordClientLatency keeps the latency of mutating requests, reads are mostly informers and waits
+func recordClientLatency(verb, path string, latency time.Duration) {
+	switch verb {
+	case "POST", "PUT", "PATCH", "DELETE":
+	default:
+		return
+	}
+	key := clientLatencyKey{resource: resourceFromPath(path), verb: verb}
+	clientLatencyLock.Lock()
+	defer clientLatencyLock.Unlock()
+	clientLatencies[key] = append(clientLatencies[key], framework.PodLatencyData{Name: path, Latency: latency})
+}
+
+// resourceFromPath extracts the resource, and subresource, from an API path like
+// /api/v1/namespaces/{namespace}/pods/{name}/status
+func resourceFromPath(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(parts) > 2 && parts[0] == "api":
+		parts = parts[2:]
+	case len(parts) > 3 && parts[0] == "apis":
+		parts = parts[3:]
+	default:
+		return "other"
+	}
+	// Namespaces themselves are a resource as well, so only strip the
+	// namespace when a namespaced resource follows it
+	if len(parts) > 2 && parts[0] == "namespaces" && parts[2] != "finalize" && parts[2] != "status" {
+		parts = parts[2:]
+	}
+	if len(parts) > 2 {
+		return parts[0] + "/" + parts[2]
+	}
+	return parts[0]
+}
+
+// ClientLatenciesSummary returns the latencies of the mutating requests sent so far
+func ClientLatenciesSummary() *ClientLatencySummary {
+	clientLatencyLock.Lock()
+	defer clientLatencyLock.Unlock()
+	summary := &ClientLatencySummary{}
+	for key, latencies := range clientLatencies {
+		summary.Latencies = append(summary.Latencies, ClientLatency{
+			Resource: key.resource,
+			Verb:     key.verb,
+			Count:    len(latencies),
+			Latency:  latencyMetric(append([]framework.PodLatencyData{}, latencies...)),
+		})
+	}
+	sort.Sort(clientLatenciesByResource(summary.Latencies))
+	return summary
+}
+
+// ClientLatency is the latency of the requests with one verb for one resource
+type ClientLatency struct {
+	Resource string                  `json:"resource"`
+	Verb     string                  `json:"verb"`
+	Count    int                     `json:"count"`
+	Latency  framework.LatencyMetric `json:"latency"`
+}
+
+type clientLatenciesByResource []ClientLatency
+
+func (l clientLatenciesByResource) Len() int      { return len(l) }
+func (l clientLatenciesByResource) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
+func (l clientLatenciesByResource) Less(i, j int) bool {
+	if l[i].Resource != l[j].Resource {
+		return l[i].Resource < l[j].Resource
+	}
+	return l[i].Verb < l[j].Verb
+}
+
+// ClientLatencySummary holds the latencies of the mutating requests as observed by the clients,
+// including the network and the retries of 429 responses. The wait for the client-side rate limiter
+// before the first attempt isn't included, client-go starts timing the request after it.
+type ClientLatencySummary struct {
+	Latencies []ClientLatency `json:"latencies"`
+}
+
+// SummaryKind returns the name of the summary
+func (s *ClientLatencySummary) SummaryKind() string {
+	return "ClientLatency"
+}
+
+// PrintHumanReadable prints the summary as a table
+func (s *ClientLatencySummary) PrintHumanReadable() string {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 1, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "Resource\tVerb\tCount\tPerc50\tPerc90\tPerc99\tPerc100\n")
+	for _, l := range s.Latencies {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n", l.Resource, l.Verb, l.Count, l.Latency.Perc50, l.Latency.Perc90, l.Latency.Perc99, l.Latency.Perc100)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// PrintJSON prints the summary as json
+func (s *ClientLatencySummary) PrintJSON() string {
+	return framework.PrettyPrintJSON(s)
+}
+
+// PerfData converts the latencies into perfdash data items, one per resource and verb
+func (s *ClientLatencySummary) PerfData() *perftype.PerfData {
+	perfData := &perftype.PerfData{Version: currentPerfDataVersion}
+	for _, l := range s.Latencies {
+		perfData.DataItems = append(perfData.DataItems, latencyToDataItem(l.Latency, map[string]string{"Resource": l.Resource, "Verb": l.Verb}))
+	}
+	return perfData
+}
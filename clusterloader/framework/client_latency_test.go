@@ -0,0 +1,43 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import "testing"
+
+func TestResourceFromPath(t *testing.T) {
+	for _, test := range []struct {
+		path     string
+		expected string
+	}{
+		{"/api/v1/namespaces/ns/pods", "pods"},
+		{"/api/v1/namespaces/ns/pods/pod-1", "pods"},
+		{"/api/v1/namespaces/ns/pods/pod-1/status", "pods/status"},
+		{"/api/v1/nodes/node-1", "nodes"},
+		{"/api/v1/namespaces", "namespaces"},
+		{"/api/v1/namespaces/ns", "namespaces"},
+		{"/api/v1/namespaces/ns/finalize", "namespaces/finalize"},
+		{"/api/v1/namespaces/ns/status", "namespaces/status"},
+		{"/apis/extensions/v1beta1/namespaces/ns/deployments/d/scale", "deployments/scale"},
+		{"/apis/batch/v1/jobs", "jobs"},
+		{"/version", "other"},
+		{"/apis/batch", "other"},
+	} {
+		if got := resourceFromPath(test.path); got != test.expected {
+			t.Errorf("resourceFromPath(%q) = %q, expected %q", test.path, got, test.expected)
+		}
+	}
+}
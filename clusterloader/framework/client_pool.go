@@ -0,0 +1,124 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"sync/atomic"
+
+	restclient "k8s.io/client-go/rest"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+var (
+	clientPool []clientset.Interface
+	// identityPool replaces clientPool while a project with identities is created
+	identityPool []clientset.Interface
+	// identities are the identities of identityPool, kubectl impersonates them for templates
+	identities   []Identity
+	nextClient   uint32
+	nextIdentity uint32
+)
+
+// Identity is a user, and optionally groups, object operations are impersonated as
+type Identity struct {
+	User   string
+	Groups []string
+}
+
+// SetClientLimits applies the --kube-api-qps and --kube-api-burst flags to the framework client
+func SetClientLimits(f *framework.Framework) {
+	f.Options.ClientQPS = float32(kubeAPIQPS)
+	f.Options.ClientBurst = kubeAPIBurst
+}
+
+// newObjectClient creates a client limited by the flags, impersonating the identity if it has a user
+func newObjectClient(identity Identity) (clientset.Interface, error) {
+	config, err := framework.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	config.QPS = float32(kubeAPIQPS)
+	config.Burst = kubeAPIBurst
+	if framework.TestContext.KubeAPIContentType != "" {
+		config.ContentType = framework.TestContext.KubeAPIContentType
+	}
+	if identity.User != "" {
+		config.Impersonate = restclient.ImpersonationConfig{UserName: identity.User, Groups: identity.Groups}
+	}
+	return clientset.NewForConfig(config)
+}
+
+// SetupClientPool creates the --kube-api-clients clients object operations are round-robined
+// across, so the load isn't capped by the rate limits of a single client
+func SetupClientPool() error {
+	clientPool = nil
+	if kubeAPIClients <= 1 {
+		return nil
+	}
+	for i := 0; i < kubeAPIClients; i++ {
+		c, err := newObjectClient(Identity{})
+		if err != nil {
+			return err
+		}
+		clientPool = append(clientPool, c)
+	}
+	framework.Logf("Created a pool of %d clients with qps %v and burst %d each", len(clientPool), kubeAPIQPS, kubeAPIBurst)
+	return nil
+}
+
+// SetIdentities makes the following object operations round-robin across clients impersonating
+// the identities, no identities switch back to the client pool
+func SetIdentities(projectIdentities []Identity) error {
+	identityPool = nil
+	identities = nil
+	for _, identity := range projectIdentities {
+		c, err := newObjectClient(identity)
+		if err != nil {
+			return err
+		}
+		identityPool = append(identityPool, c)
+		identities = append(identities, identity)
+	}
+	return nil
+}
+
+// KubectlIdentityArgs returns the kubectl flags impersonating the next identity, round-robin,
+// or none when the project has no identities
+func KubectlIdentityArgs() []string {
+	if len(identities) == 0 {
+		return nil
+	}
+	identity := identities[atomic.AddUint32(&nextIdentity, 1)%uint32(len(identities))]
+	args := []string{"--as=" + identity.User}
+	for _, group := range identity.Groups {
+		args = append(args, "--as-group="+group)
+	}
+	return args
+}
+
+// objectClient returns the next client of the identities or the pool, or the framework client if there is neither
+func objectClient(f *framework.Framework) clientset.Interface {
+	pool := clientPool
+	if len(identityPool) > 0 {
+		pool = identityPool
+	}
+	if len(pool) == 0 {
+		return f.ClientSet
+	}
+	return pool[atomic.AddUint32(&nextClient, 1)%uint32(len(pool))]
+}
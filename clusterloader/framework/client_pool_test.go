@@ -0,0 +1,46 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKubectlIdentityArgs(t *testing.T) {
+	identities = nil
+	if args := KubectlIdentityArgs(); args != nil {
+		t.Errorf("expected no flags without identities, got %v", args)
+	}
+
+	identities = []Identity{{User: "tenant-a", Groups: []string{"tenants", "team-a"}}, {User: "tenant-b"}}
+	defer func() {
+		identities = nil
+	}()
+	seen := make(map[string][]string)
+	for i := 0; i < 4; i++ {
+		args := KubectlIdentityArgs()
+		seen[args[0]] = args
+	}
+	expected := map[string][]string{
+		"--as=tenant-a": {"--as=tenant-a", "--as-group=tenants", "--as-group=team-a"},
+		"--as=tenant-b": {"--as=tenant-b"},
+	}
+	if !reflect.DeepEqual(seen, expected) {
+		t.Errorf("expected the flags of every identity %v, got %v", expected, seen)
+	}
+}
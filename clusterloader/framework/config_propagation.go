@@ -0,0 +1,302 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/kubernetes/pkg/api/v1"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	"k8s.io/kubernetes/test/e2e/framework"
+	"k8s.io/kubernetes/test/e2e/perftype"
+)
+
+const (
+	configPropagationName         = "propagation-probe"
+	defaultConfigPropagationImage = "gcr.io/google_containers/busybox:1.24"
+	defaultConfigPropagationCount = 10
+	defaultConfigPropagationWait  = 5 * time.Minute
+	// configPropagationPoll is also the resolution of the measured latency
+	configPropagationPoll = time.Second
+	// configPropagationScript prints the content of the mounted value whenever it changes
+	configPropagationScript = `last=; while true; do v=$(cat /probe/value); if [ "$v" != "$last" ]; then echo "$v"; last="$v"; fi; sleep 0.1; done`
+)
+
+// configPropagationMeasurement updates a ConfigMap or Secret mounted by a probe pod in a sample of the
+// Cluster Loader namespaces and records how long the kubelets take to make the new content visible
+type configPropagationMeasurement struct {
+	c       clientset.Interface
+	arch    ClusterArch
+	secret  bool
+	samples int
+	image   string
+	timeout time.Duration
+}
+
+func newConfigPropagationMeasurement(config MeasurementConfig) (*configPropagationMeasurement, error) {
+	m := &configPropagationMeasurement{
+		samples: defaultConfigPropagationCount,
+		image:   defaultConfigPropagationImage,
+		timeout: defaultConfigPropagationWait,
+	}
+	switch strings.ToLower(config.Params["kind"]) {
+	case "", "configmap":
+	case "secret":
+		m.secret = true
+	default:
+		return nil, fmt.Errorf("kind must be configmap or secret, got %q", config.Params["kind"])
+	}
+	if samples, ok := config.Params["samples"]; ok {
+		value, err := strconv.Atoi(samples)
+		if err != nil {
+			return nil, err
+		}
+		if value <= 0 {
+			return nil, fmt.Errorf("samples must be positive, got %d", value)
+		}
+		m.samples = value
+	}
+	if image, ok := config.Params["image"]; ok {
+		m.image = image
+	}
+	if timeout, ok := config.Params["timeout"]; ok {
+		duration, err := time.ParseDuration(timeout)
+		if err != nil {
+			return nil, err
+		}
+		m.timeout = duration
+	}
+	return m, nil
+}
+
+func (m *configPropagationMeasurement) kind() string {
+	if m.secret {
+		return "Secret"
+	}
+	return "ConfigMap"
+}
+
+// Start only keeps the client and detects the node architectures, the probes are created in Stop
+// once the namespaces are known
+func (m *configPropagationMeasurement) Start(c clientset.Interface) error {
+	m.c = c
+	arch, err := DetectArch(c)
+	if err != nil {
+		return err
+	}
+	m.arch = arch
+	return nil
+}
+
+// Stop creates the probes in namespaces spread evenly over the given ones, updates their content
+// at once and waits until every probe pod sees the new content or the timeout passes. Both ends of the
+// latency are taken on the clock of the test, the new content is seen when the logs of the probe show it.
+func (m *configPropagationMeasurement) Stop(namespaces []*v1.Namespace) (framework.TestDataSummary, error) {
+	sampled := sampleNamespaces(namespaces, m.samples)
+	defer m.cleanup(sampled)
+	for _, ns := range sampled {
+		if err := m.createProbe(ns.Name); err != nil {
+			return nil, err
+		}
+	}
+	for _, ns := range sampled {
+		if err := framework.WaitForPodNameRunningInNamespace(m.c, configPropagationName, ns.Name); err != nil {
+			return nil, fmt.Errorf("probe pod in %v is not running: %v", ns.Name, err)
+		}
+	}
+
+	updated := make(map[string]time.Time, len(sampled))
+	for _, ns := range sampled {
+		if err := m.setValue(ns.Name, "updated", true); err != nil {
+			return nil, err
+		}
+		updated[ns.Name] = time.Now()
+	}
+	seen := make(map[string]time.Time, len(sampled))
+	err := wait.Poll(configPropagationPoll, m.timeout, func() (bool, error) {
+		for _, ns := range sampled {
+			if _, ok := seen[ns.Name]; ok {
+				continue
+			}
+			if m.visible(ns.Name) {
+				seen[ns.Name] = time.Now()
+			}
+		}
+		return len(seen) == len(sampled), nil
+	})
+	if err != nil && err != wait.ErrWaitTimeout {
+		return nil, err
+	}
+
+	summary := &ConfigPropagationSummary{Kind: m.kind(), Samples: len(sampled)}
+	var latencies []framework.PodLatencyData
+	for _, ns := range sampled {
+		visible, ok := seen[ns.Name]
+		if !ok {
+			summary.NotPropagated = append(summary.NotPropagated, ns.Name)
+			continue
+		}
+		latencies = append(latencies, framework.PodLatencyData{Name: ns.Name, Latency: visible.Sub(updated[ns.Name])})
+	}
+	sort.Strings(summary.NotPropagated)
+	summary.Latency = latencyMetric(latencies)
+	return summary, nil
+}
+
+// sampleNamespaces picks up to count namespaces evenly spread over the list
+func sampleNamespaces(namespaces []*v1.Namespace, count int) []*v1.Namespace {
+	if len(namespaces) <= count {
+		return namespaces
+	}
+	sampled := make([]*v1.Namespace, 0, count)
+	for i := 0; i < count; i++ {
+		sampled = append(sampled, namespaces[i*len(namespaces)/count])
+	}
+	return sampled
+}
+
+func (m *configPropagationMeasurement) createProbe(namespace string) error {
+	if err := m.setValue(namespace, "initial", false); err != nil {
+		return err
+	}
+	source := v1.VolumeSource{ConfigMap: &v1.ConfigMapVolumeSource{LocalObjectReference: v1.LocalObjectReference{Name: configPropagationName}}}
+	if m.secret {
+		source = v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: configPropagationName}}
+	}
+	zero := int64(0)
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: configPropagationName},
+		Spec: v1.PodSpec{
+			TerminationGracePeriodSeconds: &zero,
+			Containers: []v1.Container{
+				{
+					Name:         "probe",
+					Image:        m.image,
+					Command:      []string{"/bin/sh", "-c", configPropagationScript},
+					VolumeMounts: []v1.VolumeMount{{Name: "probe", MountPath: "/probe"}},
+				},
+			},
+			Volumes: []v1.Volume{{Name: "probe", VolumeSource: source}},
+		},
+	}
+	m.arch.RewritePodSpec(&pod.Spec)
+	return retryWithBackoff(fmt.Sprintf("creating probe pod %v/%v", namespace, configPropagationName), func() error {
+		_, err := m.c.Core().Pods(namespace).Create(pod)
+		return err
+	})
+}
+
+// setValue creates the ConfigMap or Secret with the value, or updates it
+func (m *configPropagationMeasurement) setValue(namespace, value string, update bool) error {
+	meta := metav1.ObjectMeta{Name: configPropagationName}
+	return retryWithBackoff(fmt.Sprintf("setting %v %v/%v", m.kind(), namespace, configPropagationName), func() error {
+		var err error
+		switch {
+		case m.secret && update:
+			_, err = m.c.Core().Secrets(namespace).Update(&v1.Secret{ObjectMeta: meta, Data: map[string][]byte{"value": []byte(value)}})
+		case m.secret:
+			_, err = m.c.Core().Secrets(namespace).Create(&v1.Secret{ObjectMeta: meta, Data: map[string][]byte{"value": []byte(value)}})
+		case update:
+			_, err = m.c.Core().ConfigMaps(namespace).Update(&v1.ConfigMap{ObjectMeta: meta, Data: map[string]string{"value": value}})
+		default:
+			_, err = m.c.Core().ConfigMaps(namespace).Create(&v1.ConfigMap{ObjectMeta: meta, Data: map[string]string{"value": value}})
+		}
+		return err
+	})
+}
+
+// visible checks whether the probe pod printed the updated value to its logs
+func (m *configPropagationMeasurement) visible(namespace string) bool {
+	logs, err := m.c.Core().Pods(namespace).GetLogs(configPropagationName, &v1.PodLogOptions{}).Do().Raw()
+	if err != nil {
+		framework.Logf("Error reading the logs of the probe pod in %v: %v", namespace, err)
+		return false
+	}
+	for _, line := range strings.Split(string(logs), "\n") {
+		if strings.TrimSpace(line) == "updated" {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanup deletes the probes, existing namespaces used by the projects aren't deleted with the test
+func (m *configPropagationMeasurement) cleanup(namespaces []*v1.Namespace) {
+	for _, ns := range namespaces {
+		if err := m.c.Core().Pods(ns.Name).Delete(configPropagationName, metav1.NewDeleteOptions(0)); err != nil {
+			framework.Logf("Error deleting the probe pod in %v: %v", ns.Name, err)
+		}
+		var err error
+		if m.secret {
+			err = m.c.Core().Secrets(ns.Name).Delete(configPropagationName, nil)
+		} else {
+			err = m.c.Core().ConfigMaps(ns.Name).Delete(configPropagationName, nil)
+		}
+		if err != nil {
+			framework.Logf("Error deleting the probe %v in %v: %v", m.kind(), ns.Name, err)
+		}
+	}
+}
+
+// ConfigPropagationSummary holds the latency from updating a ConfigMap or Secret until the new content
+// was visible in the pod mounting it
+type ConfigPropagationSummary struct {
+	Kind          string                  `json:"kind"`
+	Samples       int                     `json:"samples"`
+	NotPropagated []string                `json:"notPropagated"`
+	Latency       framework.LatencyMetric `json:"latency"`
+}
+
+// SummaryKind returns the name of the summary
+func (s *ConfigPropagationSummary) SummaryKind() string {
+	return "ConfigPropagation"
+}
+
+// PrintHumanReadable prints the summary as a table
+func (s *ConfigPropagationSummary) PrintHumanReadable() string {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 1, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "%v propagation in %v namespaces, not propagated: %v\n", s.Kind, s.Samples, len(s.NotPropagated))
+	fmt.Fprintf(w, "Latency\tPerc50\tPerc90\tPerc99\tPerc100\n")
+	fmt.Fprintf(w, "update_to_visible\t%v\t%v\t%v\t%v\n", s.Latency.Perc50, s.Latency.Perc90, s.Latency.Perc99, s.Latency.Perc100)
+	for _, name := range s.NotPropagated {
+		fmt.Fprintf(w, "Not propagated: %v\n", name)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// PrintJSON prints the summary as json
+func (s *ConfigPropagationSummary) PrintJSON() string {
+	return framework.PrettyPrintJSON(s)
+}
+
+// PerfData converts the propagation latency into a perfdash data item
+func (s *ConfigPropagationSummary) PerfData() *perftype.PerfData {
+	return &perftype.PerfData{
+		Version:   currentPerfDataVersion,
+		DataItems: []perftype.DataItem{latencyToDataItem(s.Latency, map[string]string{"Metric": "update_to_visible", "Kind": s.Kind})},
+	}
+}
@@ -17,16 +17,25 @@ limitations under the License.
 package framework
 
 import (
+	"fmt"
 	"os"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 )
 
 // Context is the root config struct
 type Context struct {
 	ClusterLoader struct {
-		Projects   []ClusterLoader
-		TuningSets []TuningSet
+		Projects     []ClusterLoader
+		TuningSets   []TuningSet
+		Measurements []MeasurementConfig
+		Warmup       WarmupConfig
+		Churn        ChurnConfig
+		Preflight    PreflightConfig
+		Pushgateway  PushgatewayConfig
+		Timing       TimingConfig
+		Grafana      GrafanaConfig
 	}
 }
 
@@ -35,12 +44,25 @@ type TuningSets []TuningSet
 
 // ClusterLoader struct only used for Cluster Loader test config
 type ClusterLoader struct {
-	Number    int `mapstructure:"num"`
-	Basename  string
-	Tuning    string
-	Pods      []ClusterLoaderObject
-	RCs       []ClusterLoaderObject
-	Templates []ClusterLoaderObject
+	Number     int `mapstructure:"num"`
+	Basename   string
+	Tuning     string
+	Hooks      NamespaceHooks
+	Pods       []ClusterLoaderObject
+	RCs        []ClusterLoaderObject
+	Templates  []ClusterLoaderObject
+	Identities []Identity
+	Namespace  NamespaceConfig
+	// Namespaces are existing namespaces the project runs in instead of creating num new ones,
+	// they are not deleted at the end of the test
+	Namespaces []string
+	Exec       ProjectExec
+}
+
+// NamespaceConfig is the metadata applied to every namespace of a project
+type NamespaceConfig struct {
+	Labels      map[string]string
+	Annotations map[string]string
 }
 
 // ClusterLoaderObject is nested object type for cluster loader struct
@@ -51,6 +73,9 @@ type ClusterLoaderObject struct {
 	Basename string
 	File     string
 	Label    string
+	// Min and Max replace num with a count sampled uniformly for every namespace
+	Min int
+	Max int
 }
 
 // TuningSet is nested type for controlling Cluster Loader deployment pattern
@@ -71,16 +96,90 @@ type TuningSetObject struct {
 	RateLimit struct {
 		Delay string
 	}
+	// Parallelism is the number of namespaces created at once, only used by project tuning
+	Parallelism int
+	// Quota makes pod creation wait for room in the ResourceQuotas of the namespace instead of failing
+	Quota struct {
+		Wait    bool
+		Timeout string
+	}
+}
+
+// MeasurementConfig selects a measurement to run alongside the projects
+type MeasurementConfig struct {
+	Name   string
+	Params map[string]string
+}
+
+// WarmupConfig controls the warmup wave of pods created before the projects
+type WarmupConfig struct {
+	PodsPerNode int
+	Image       string
+	Settle      string
+}
+
+// ChurnConfig controls the pods created and deleted in the background while the projects are created
+type ChurnConfig struct {
+	// Pods is the size of the churned population, 0 disables the churn
+	Pods  int
+	Image string
+	// Interval is the time between replacing the oldest pod with a new one
+	Interval string
+}
+
+// PreflightConfig guards against accidentally loading the wrong cluster
+type PreflightConfig struct {
+	// MaxNodes is the largest cluster the test runs against without confirmation, 0 disables the check
+	MaxNodes int
+}
+
+// PushgatewayConfig selects the Prometheus Pushgateway the measurement results are pushed to
+type PushgatewayConfig struct {
+	URL string
+	Job string
+	// Labels are attached to every pushed metric, e.g. the test name or the commit
+	Labels map[string]string
+}
+
+// TimingConfig controls the timing report of the run
+type TimingConfig struct {
+	// Trace additionally writes the timing as a Chrome trace into the report dir
+	Trace bool
+}
+
+// GrafanaConfig selects the Grafana instance the phases of the run are annotated in
+type GrafanaConfig struct {
+	URL    string
+	APIKey string
+	Tags   []string
 }
 
 // ConfigContext variable of type Context
 var ConfigContext Context
 
-// ParseConfig will complete flag parsing as well as viper tasks
-func ParseConfig(config string) {
+// ParseConfig will complete flag parsing as well as viper tasks, unknown keys in the
+// ClusterLoader section and invalid values are reported as errors
+func ParseConfig(config string) error {
 	// This must be done after common flags are registered, since Viper is a flag option.
 	viper.SetConfigName(config)
 	viper.AddConfigPath(os.Getenv("GOPATH") + "/src/k8s.io/perf-tests/clusterloader")
-	viper.ReadInConfig()
-	viper.Unmarshal(&ConfigContext)
+	if err := viper.ReadInConfig(); err != nil {
+		return fmt.Errorf("error reading config %v: %v", config, err)
+	}
+	// The other top level keys belong to the e2e framework, they can't be checked here
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		ErrorUnused:      true,
+		WeaklyTypedInput: true,
+		Result:           &ConfigContext.ClusterLoader,
+	})
+	if err != nil {
+		return err
+	}
+	if err := decoder.Decode(viper.Get("clusterloader")); err != nil {
+		return fmt.Errorf("error parsing config %v: %v", config, err)
+	}
+	if err := ConfigContext.Validate(); err != nil {
+		return fmt.Errorf("invalid config %v: %v", config, err)
+	}
+	return nil
 }
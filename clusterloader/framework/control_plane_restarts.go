@@ -0,0 +1,250 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kubernetes/pkg/api/v1"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+const defaultControlPlaneSelector = "tier=control-plane"
+
+// oomEventReasons are the reasons of the events kubelets report about system OOMs and OOM kills
+var oomEventReasons = map[string]bool{"SystemOOM": true, "OOMKilling": true}
+
+// controlPlaneRestartsMeasurement watches control-plane pods for container restarts and all
+// events for node OOMs, so crashes in the middle of the run don't go unnoticed
+type controlPlaneRestartsMeasurement struct {
+	selector      string
+	failOnRestart bool
+	lock          sync.Mutex
+	start         time.Time
+	containers    map[string]*containerRestarts
+	ooms          map[string]*NodeOOM
+	podStopCh     chan struct{}
+	eventStopCh   chan struct{}
+}
+
+type containerRestarts struct {
+	baseline int32
+	restart  ContainerRestart
+}
+
+func newControlPlaneRestartsMeasurement(config MeasurementConfig) (*controlPlaneRestartsMeasurement, error) {
+	m := &controlPlaneRestartsMeasurement{selector: defaultControlPlaneSelector}
+	if selector, ok := config.Params["selector"]; ok {
+		m.selector = selector
+	}
+	if fail, ok := config.Params["failonrestart"]; ok {
+		value, err := strconv.ParseBool(fail)
+		if err != nil {
+			return nil, err
+		}
+		m.failOnRestart = value
+	}
+	return m, nil
+}
+
+// Start watches the control-plane pods in kube-system and the events in all namespaces
+func (m *controlPlaneRestartsMeasurement) Start(c clientset.Interface) error {
+	m.start = time.Now()
+	m.containers = make(map[string]*containerRestarts)
+	m.ooms = make(map[string]*NodeOOM)
+	m.podStopCh = startInformer(&cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.LabelSelector = m.selector
+			return c.Core().Pods(metav1.NamespaceSystem).List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.LabelSelector = m.selector
+			return c.Core().Pods(metav1.NamespaceSystem).Watch(options)
+		},
+	}, &v1.Pod{}, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			m.updatePod(obj.(*v1.Pod))
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			m.updatePod(obj.(*v1.Pod))
+		},
+	})
+	m.eventStopCh = startInformer(&cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return c.Core().Events(metav1.NamespaceAll).List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return c.Core().Events(metav1.NamespaceAll).Watch(options)
+		},
+	}, &v1.Event{}, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			m.updateEvent(obj.(*v1.Event))
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			m.updateEvent(obj.(*v1.Event))
+		},
+	})
+	return nil
+}
+
+func (m *controlPlaneRestartsMeasurement) updatePod(pod *v1.Pod) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for _, status := range pod.Status.ContainerStatuses {
+		key := string(pod.UID) + "/" + status.Name
+		container, ok := m.containers[key]
+		if !ok {
+			container = &containerRestarts{restart: ContainerRestart{Pod: pod.Name, Container: status.Name, Node: pod.Spec.NodeName}}
+			// Restarts from before the run don't count, unless the pod was created during the run
+			if pod.CreationTimestamp.Time.Before(m.start) {
+				container.baseline = status.RestartCount
+			}
+			m.containers[key] = container
+		}
+		container.restart.Restarts = int(status.RestartCount - container.baseline)
+		if terminated := status.LastTerminationState.Terminated; terminated != nil && container.restart.Restarts > 0 {
+			container.restart.LastReason = terminated.Reason
+			container.restart.LastExitCode = terminated.ExitCode
+			container.restart.LastFinished = terminated.FinishedAt.Time
+		}
+	}
+}
+
+func (m *controlPlaneRestartsMeasurement) updateEvent(event *v1.Event) {
+	if !oomEventReasons[event.Reason] || event.LastTimestamp.Time.Before(m.start) {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	key := event.Namespace + "/" + event.Name
+	oom, ok := m.ooms[key]
+	if !ok {
+		oom = &NodeOOM{Node: event.Source.Host, Reason: event.Reason}
+		if oom.Node == "" {
+			oom.Node = event.InvolvedObject.Name
+		}
+		m.ooms[key] = oom
+	}
+	oom.Count = int(event.Count)
+	oom.Message = event.Message
+	oom.Last = event.LastTimestamp.Time
+}
+
+// Stop lists the restarts and OOMs seen during the run, it fails if restarts are found and failonrestart is set
+func (m *controlPlaneRestartsMeasurement) Stop(_ []*v1.Namespace) (framework.TestDataSummary, error) {
+	close(m.podStopCh)
+	close(m.eventStopCh)
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	summary := &ControlPlaneRestartsSummary{}
+	for _, container := range m.containers {
+		if container.restart.Restarts > 0 {
+			summary.Restarts = append(summary.Restarts, container.restart)
+			framework.Logf("Container %v of %v restarted %d times during the run, last reason %q", container.restart.Container, container.restart.Pod, container.restart.Restarts, container.restart.LastReason)
+		}
+	}
+	for _, oom := range m.ooms {
+		summary.OOMs = append(summary.OOMs, *oom)
+	}
+	sort.Sort(byRestartedPod(summary.Restarts))
+	sort.Sort(byOOMNode(summary.OOMs))
+	if m.failOnRestart && len(summary.Restarts) > 0 {
+		PrintSummary(summary)
+		return nil, fmt.Errorf("%d control-plane containers restarted during the run", len(summary.Restarts))
+	}
+	return summary, nil
+}
+
+// ControlPlaneRestartsSummary lists the control-plane container restarts and node OOMs of the run
+type ControlPlaneRestartsSummary struct {
+	Restarts []ContainerRestart `json:"restarts"`
+	OOMs     []NodeOOM          `json:"ooms"`
+}
+
+// ContainerRestart is a control-plane container which restarted during the run
+type ContainerRestart struct {
+	Pod          string    `json:"pod"`
+	Container    string    `json:"container"`
+	Node         string    `json:"node"`
+	Restarts     int       `json:"restarts"`
+	LastReason   string    `json:"lastReason"`
+	LastExitCode int32     `json:"lastExitCode"`
+	LastFinished time.Time `json:"lastFinished"`
+}
+
+// NodeOOM is an OOM reported by the kubelet of a node during the run
+type NodeOOM struct {
+	Node    string    `json:"node"`
+	Reason  string    `json:"reason"`
+	Count   int       `json:"count"`
+	Message string    `json:"message"`
+	Last    time.Time `json:"last"`
+}
+
+type byRestartedPod []ContainerRestart
+
+func (r byRestartedPod) Len() int      { return len(r) }
+func (r byRestartedPod) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r byRestartedPod) Less(i, j int) bool {
+	if r[i].Pod != r[j].Pod {
+		return r[i].Pod < r[j].Pod
+	}
+	return r[i].Container < r[j].Container
+}
+
+type byOOMNode []NodeOOM
+
+func (o byOOMNode) Len() int           { return len(o) }
+func (o byOOMNode) Swap(i, j int)      { o[i], o[j] = o[j], o[i] }
+func (o byOOMNode) Less(i, j int) bool { return o[i].Node < o[j].Node }
+
+// SummaryKind returns the name of the summary
+func (s *ControlPlaneRestartsSummary) SummaryKind() string {
+	return "ControlPlaneRestarts"
+}
+
+// PrintHumanReadable prints the summary as tables
+func (s *ControlPlaneRestartsSummary) PrintHumanReadable() string {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 1, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "Pod\tContainer\tNode\tRestarts\tLastReason\tLastExitCode\tLastFinished\n")
+	for _, r := range s.Restarts {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n", r.Pod, r.Container, r.Node, r.Restarts, r.LastReason, r.LastExitCode, r.LastFinished.Format(time.RFC3339))
+	}
+	fmt.Fprintf(w, "\nNode\tReason\tCount\tLast\tMessage\n")
+	for _, oom := range s.OOMs {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", oom.Node, oom.Reason, oom.Count, oom.Last.Format(time.RFC3339), oom.Message)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// PrintJSON prints the summary as json
+func (s *ControlPlaneRestartsSummary) PrintJSON() string {
+	return framework.PrettyPrintJSON(s)
+}
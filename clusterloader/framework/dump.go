@@ -0,0 +1,148 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"os"
+	"path"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/api/v1"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+type logFunc func(format string, args ...interface{})
+
+// DumpClusterState writes the state relevant for debugging a failed step into
+// ReportDir/failure-<step>, or logs it when no report dir is set
+func DumpClusterState(c clientset.Interface, step string, namespaces []*v1.Namespace) {
+	dir := ""
+	if framework.TestContext.ReportDir != "" {
+		dir = path.Join(framework.TestContext.ReportDir, "failure-"+step)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			framework.Logf("Failed to create dump directory %v: %v", dir, err)
+			dir = ""
+		}
+	}
+	framework.Logf("Dumping cluster state after %v failed", step)
+	dumpToFile(dir, "pods.txt", func(log logFunc) { dumpNotRunningPods(c, namespaces, log) })
+	dumpToFile(dir, "events.txt", func(log logFunc) { dumpEvents(c, namespaces, log) })
+	dumpToFile(dir, "nodes.txt", func(log logFunc) { dumpNodeConditions(c, log) })
+	dumpToFile(dir, "componentstatuses.txt", func(log logFunc) { dumpComponentStatuses(c, log) })
+}
+
+// dumpToFile runs dump with a log function writing into dir/name, or into the test log if dir is empty
+func dumpToFile(dir, name string, dump func(log logFunc)) {
+	if dir == "" {
+		dump(func(format string, args ...interface{}) { framework.Logf(format, args...) })
+		return
+	}
+	filePath := path.Join(dir, name)
+	file, err := os.Create(filePath)
+	if err != nil {
+		framework.Logf("Failed to create %v: %v", filePath, err)
+		return
+	}
+	defer file.Close()
+	log := framework.GetLogToFileFunc(file)
+	dump(func(format string, args ...interface{}) { log(format+"\n", args...) })
+}
+
+func dumpNotRunningPods(c clientset.Interface, namespaces []*v1.Namespace, log logFunc) {
+	for _, ns := range namespaces {
+		pods, err := c.Core().Pods(ns.Name).List(metav1.ListOptions{})
+		if err != nil {
+			log("Failed to list pods in %v: %v", ns.Name, err)
+			continue
+		}
+		for _, pod := range pods.Items {
+			if pod.Status.Phase == v1.PodRunning || pod.Status.Phase == v1.PodSucceeded {
+				continue
+			}
+			log("%v/%v phase=%v node=%q reason=%q message=%q", pod.Namespace, pod.Name, pod.Status.Phase, pod.Spec.NodeName, pod.Status.Reason, pod.Status.Message)
+			for _, condition := range pod.Status.Conditions {
+				log("  condition %v=%v reason=%q message=%q", condition.Type, condition.Status, condition.Reason, condition.Message)
+			}
+			for _, status := range pod.Status.ContainerStatuses {
+				if status.State.Waiting != nil {
+					log("  container %v waiting reason=%q message=%q", status.Name, status.State.Waiting.Reason, status.State.Waiting.Message)
+				}
+				if status.State.Terminated != nil {
+					log("  container %v terminated reason=%q exitCode=%v", status.Name, status.State.Terminated.Reason, status.State.Terminated.ExitCode)
+				}
+			}
+		}
+	}
+}
+
+type eventsByLastTimestamp []v1.Event
+
+func (e eventsByLastTimestamp) Len() int      { return len(e) }
+func (e eventsByLastTimestamp) Swap(i, j int) { e[i], e[j] = e[j], e[i] }
+func (e eventsByLastTimestamp) Less(i, j int) bool {
+	return e[i].LastTimestamp.Before(e[j].LastTimestamp)
+}
+
+func dumpEvents(c clientset.Interface, namespaces []*v1.Namespace, log logFunc) {
+	// Node events are reported in the default namespace
+	names := []string{metav1.NamespaceDefault, metav1.NamespaceSystem}
+	for _, ns := range namespaces {
+		names = append(names, ns.Name)
+	}
+	var events []v1.Event
+	for _, name := range names {
+		list, err := c.Core().Events(name).List(metav1.ListOptions{})
+		if err != nil {
+			log("Failed to list events in %v: %v", name, err)
+			continue
+		}
+		events = append(events, list.Items...)
+	}
+	sort.Sort(eventsByLastTimestamp(events))
+	for _, e := range events {
+		log("%v %v %v/%v %v: %v (x%d)", e.LastTimestamp, e.Type, e.InvolvedObject.Kind, e.InvolvedObject.Name, e.Reason, e.Message, e.Count)
+	}
+}
+
+func dumpNodeConditions(c clientset.Interface, log logFunc) {
+	nodes, err := c.Core().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		log("Failed to list nodes: %v", err)
+		return
+	}
+	for _, node := range nodes.Items {
+		log("%v unschedulable=%v", node.Name, node.Spec.Unschedulable)
+		for _, condition := range node.Status.Conditions {
+			log("  %v=%v reason=%q message=%q", condition.Type, condition.Status, condition.Reason, condition.Message)
+		}
+	}
+}
+
+func dumpComponentStatuses(c clientset.Interface, log logFunc) {
+	statuses, err := c.Core().ComponentStatuses().List(metav1.ListOptions{})
+	if err != nil {
+		log("Failed to list component statuses: %v", err)
+		return
+	}
+	for _, status := range statuses.Items {
+		for _, condition := range status.Conditions {
+			log("%v %v=%v message=%q error=%q", status.Name, condition.Type, condition.Status, condition.Message, condition.Error)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kubernetes/pkg/api/v1"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+const defaultEventSpikeThreshold = 10
+
+// anomalyReasons are the event reasons which are flagged when their rate spikes
+var anomalyReasons = []string{"FailedScheduling", "BackOff", "FailedCreate", "FailedMount", "FailedSync", "Evicted"}
+
+// eventCountsMeasurement counts events by reason and source during the run, every event
+// is counted as many times as it was repeated within the window
+type eventCountsMeasurement struct {
+	spikeThreshold int
+	lock           sync.Mutex
+	start          time.Time
+	lastCount      map[string]int32
+	byReason       map[string]int
+	bySource       map[string]int
+	// perMinute counts events of every reason in one minute buckets since start
+	perMinute map[string]map[int]int
+	stopCh    chan struct{}
+}
+
+func newEventCountsMeasurement(config MeasurementConfig) (*eventCountsMeasurement, error) {
+	m := &eventCountsMeasurement{spikeThreshold: defaultEventSpikeThreshold}
+	if threshold, ok := config.Params["spikethreshold"]; ok {
+		value, err := strconv.Atoi(threshold)
+		if err != nil {
+			return nil, err
+		}
+		m.spikeThreshold = value
+	}
+	return m, nil
+}
+
+// Start watches events in all namespaces
+func (m *eventCountsMeasurement) Start(c clientset.Interface) error {
+	m.start = time.Now()
+	m.lastCount = make(map[string]int32)
+	m.byReason = make(map[string]int)
+	m.bySource = make(map[string]int)
+	m.perMinute = make(map[string]map[int]int)
+	m.stopCh = startInformer(&cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return c.Core().Events(metav1.NamespaceAll).List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return c.Core().Events(metav1.NamespaceAll).Watch(options)
+		},
+	}, &v1.Event{}, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			m.update(obj.(*v1.Event))
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			m.update(obj.(*v1.Event))
+		},
+	})
+	return nil
+}
+
+func (m *eventCountsMeasurement) update(event *v1.Event) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	key := event.Namespace + "/" + event.Name
+	last, seen := m.lastCount[key]
+	m.lastCount[key] = event.Count
+	if !seen && event.LastTimestamp.Time.Before(m.start) {
+		// The event happened before the run, only repetitions from now on are counted
+		return
+	}
+	delta := int(event.Count - last)
+	if delta <= 0 {
+		return
+	}
+	m.byReason[event.Reason] += delta
+	m.bySource[event.Source.Component] += delta
+	if _, ok := m.perMinute[event.Reason]; !ok {
+		m.perMinute[event.Reason] = make(map[int]int)
+	}
+	m.perMinute[event.Reason][int(time.Since(m.start).Minutes())] += delta
+}
+
+// Stop summarizes the events from the whole cluster, events about nodes and system
+// components are not namespaced to the Cluster Loader namespaces so all are kept
+func (m *eventCountsMeasurement) Stop(_ []*v1.Namespace) (framework.TestDataSummary, error) {
+	close(m.stopCh)
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	summary := &EventCountsSummary{
+		Duration: time.Since(m.start),
+		ByReason: m.byReason,
+		BySource: m.bySource,
+	}
+	for _, reason := range anomalyReasons {
+		peak := 0
+		for _, count := range m.perMinute[reason] {
+			if count > peak {
+				peak = count
+			}
+		}
+		if peak > m.spikeThreshold {
+			summary.Anomalies = append(summary.Anomalies, EventAnomaly{Reason: reason, Count: m.byReason[reason], PeakPerMinute: peak})
+			framework.Logf("Spike of %v events detected: %d in one minute, %d in total", reason, peak, m.byReason[reason])
+		}
+	}
+	return summary, nil
+}
+
+// EventCountsSummary holds the histogram of events observed during the run
+type EventCountsSummary struct {
+	Duration  time.Duration  `json:"duration"`
+	ByReason  map[string]int `json:"byReason"`
+	BySource  map[string]int `json:"bySource"`
+	Anomalies []EventAnomaly `json:"anomalies"`
+}
+
+// EventAnomaly is an event reason whose per minute rate exceeded the spike threshold
+type EventAnomaly struct {
+	Reason        string `json:"reason"`
+	Count         int    `json:"count"`
+	PeakPerMinute int    `json:"peakPerMinute"`
+}
+
+// SummaryKind returns the name of the summary
+func (s *EventCountsSummary) SummaryKind() string {
+	return "EventCounts"
+}
+
+// PrintHumanReadable prints the summary as a table
+func (s *EventCountsSummary) PrintHumanReadable() string {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 1, 0, 1, ' ', 0)
+	minutes := s.Duration.Minutes()
+	for _, histogram := range []struct {
+		name   string
+		counts map[string]int
+	}{{"Reason", s.ByReason}, {"Source", s.BySource}} {
+		var keys []string
+		for key := range histogram.counts {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fmt.Fprintf(w, "%v\tCount\tPerMinute\n", histogram.name)
+		for _, key := range keys {
+			fmt.Fprintf(w, "%v\t%v\t%.2f\n", key, histogram.counts[key], float64(histogram.counts[key])/minutes)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+	for _, anomaly := range s.Anomalies {
+		fmt.Fprintf(w, "ANOMALY: %v spiked to %v events per minute (%v in total)\n", anomaly.Reason, anomaly.PeakPerMinute, anomaly.Count)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// PrintJSON prints the summary as json
+func (s *EventCountsSummary) PrintJSON() string {
+	return framework.PrettyPrintJSON(s)
+}
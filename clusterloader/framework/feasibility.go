@@ -0,0 +1,162 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/kubernetes/pkg/api/v1"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+// podResources are the resources requested by a number of pods
+type podResources struct {
+	pods   int64
+	cpu    int64
+	memory int64
+}
+
+// nodeCapacity is the free capacity of a schedulable node
+type nodeCapacity struct {
+	labels labels.Set
+	free   podResources
+}
+
+// CheckFeasibility compares the pods and RC replicas the projects will create with the free capacity
+// of the schedulable nodes and warns about pods which are bound to stay Pending. Pods are only
+// compared with the nodes their node selector allows, including the one added to keep them off
+// Windows nodes. Templates are opaque to Cluster Loader and aren't taken into account.
+func CheckFeasibility(c clientset.Interface, projects []ClusterLoader, arch ClusterArch) error {
+	nodes, err := c.Core().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	pods, err := c.Core().Pods(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	used := make(map[string]podResources)
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == "" || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		r := podRequests(pod.Spec)
+		u := used[pod.Spec.NodeName]
+		used[pod.Spec.NodeName] = podResources{pods: u.pods + 1, cpu: u.cpu + r.cpu, memory: u.memory + r.memory}
+	}
+	var capacities []nodeCapacity
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		u := used[node.Name]
+		capacities = append(capacities, nodeCapacity{
+			labels: labels.Set(node.Labels),
+			free: podResources{
+				pods:   node.Status.Allocatable.Pods().Value() - u.pods,
+				cpu:    node.Status.Allocatable.Cpu().MilliValue() - u.cpu,
+				memory: node.Status.Allocatable.Memory().Value() - u.memory,
+			},
+		})
+	}
+
+	// The pods are summed up per node selector, each compared with the nodes the selector matches
+	var selectors []labels.Selector
+	required := make(map[string]podResources)
+	for _, p := range projects {
+		objects := append(append([]ClusterLoaderObject{}, p.Pods...), p.RCs...)
+		for _, object := range objects {
+			pod, err := object.ParseConfig()
+			if err != nil {
+				return err
+			}
+			arch.selectLinux(&pod.Spec)
+			selector := labels.SelectorFromSet(labels.Set(pod.Spec.NodeSelector))
+			_, largestNode := freeCapacity(capacities, selector)
+			r := podRequests(pod.Spec)
+			if r.cpu > largestNode.cpu || r.memory > largestNode.memory {
+				framework.Logf("WARNING: pods of %v in project %v request %dm cpu and %d bytes of memory, which doesn't fit on any node", object.Basename, p.Basename, r.cpu, r.memory)
+			}
+			namespaces := p.Number
+			if len(p.Namespaces) > 0 {
+				namespaces = len(p.Namespaces)
+			}
+			count := int64(float64(namespaces) * object.meanCount())
+			key := selector.String()
+			if _, ok := required[key]; !ok {
+				selectors = append(selectors, selector)
+			}
+			req := required[key]
+			required[key] = podResources{pods: req.pods + count, cpu: req.cpu + count*r.cpu, memory: req.memory + count*r.memory}
+		}
+	}
+	if len(selectors) == 0 {
+		selectors = append(selectors, labels.Everything())
+	}
+	for _, selector := range selectors {
+		free, _ := freeCapacity(capacities, selector)
+		req := required[selector.String()]
+		nodesName := "the schedulable nodes"
+		if !selector.Empty() {
+			nodesName = fmt.Sprintf("the schedulable nodes matching %v", selector)
+		}
+		framework.Logf("Feasibility: the projects create %d pods requesting %dm cpu and %d bytes of memory, %v have room for %d pods, %dm cpu and %d bytes of memory",
+			req.pods, req.cpu, req.memory, nodesName, free.pods, free.cpu, free.memory)
+		if req.pods > free.pods {
+			framework.Logf("WARNING: %d pods will stay Pending, %v don't have enough pod capacity", req.pods-free.pods, nodesName)
+		}
+		if req.cpu > free.cpu {
+			framework.Logf("WARNING: the pods request %dm more cpu than %v have available, some will stay Pending", req.cpu-free.cpu, nodesName)
+		}
+		if req.memory > free.memory {
+			framework.Logf("WARNING: the pods request %d bytes more memory than %v have available, some will stay Pending", req.memory-free.memory, nodesName)
+		}
+	}
+	return nil
+}
+
+// freeCapacity sums up the free capacity of the nodes matching the selector and returns the
+// largest free cpu and memory of a single one of them
+func freeCapacity(capacities []nodeCapacity, selector labels.Selector) (free, largestNode podResources) {
+	for _, capacity := range capacities {
+		if !selector.Matches(capacity.labels) {
+			continue
+		}
+		free.pods += capacity.free.pods
+		free.cpu += capacity.free.cpu
+		free.memory += capacity.free.memory
+		if capacity.free.cpu > largestNode.cpu {
+			largestNode.cpu = capacity.free.cpu
+		}
+		if capacity.free.memory > largestNode.memory {
+			largestNode.memory = capacity.free.memory
+		}
+	}
+	return free, largestNode
+}
+
+func podRequests(spec v1.PodSpec) podResources {
+	r := podResources{pods: 1}
+	for _, container := range spec.Containers {
+		r.cpu += container.Resources.Requests.Cpu().MilliValue()
+		r.memory += container.Resources.Requests.Memory().Value()
+	}
+	return r
+}
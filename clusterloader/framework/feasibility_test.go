@@ -0,0 +1,47 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestFreeCapacity(t *testing.T) {
+	capacities := []nodeCapacity{
+		{labels: labels.Set{osLabel: "linux"}, free: podResources{pods: 10, cpu: 2000, memory: 4000}},
+		{labels: labels.Set{osLabel: "linux", "pool": "large"}, free: podResources{pods: 20, cpu: 8000, memory: 1000}},
+		{labels: labels.Set{osLabel: "windows"}, free: podResources{pods: 30, cpu: 16000, memory: 16000}},
+	}
+	for _, test := range []struct {
+		name     string
+		selector labels.Selector
+		free     podResources
+		largest  podResources
+	}{
+		{"all nodes", labels.Everything(), podResources{pods: 60, cpu: 26000, memory: 21000}, podResources{cpu: 16000, memory: 16000}},
+		{"linux nodes", labels.SelectorFromSet(labels.Set{osLabel: "linux"}), podResources{pods: 30, cpu: 10000, memory: 5000}, podResources{cpu: 8000, memory: 4000}},
+		{"pool", labels.SelectorFromSet(labels.Set{"pool": "large"}), podResources{pods: 20, cpu: 8000, memory: 1000}, podResources{cpu: 8000, memory: 1000}},
+		{"no nodes", labels.SelectorFromSet(labels.Set{"pool": "gpu"}), podResources{}, podResources{}},
+	} {
+		free, largest := freeCapacity(capacities, test.selector)
+		if free != test.free || largest != test.largest {
+			t.Errorf("%v: expected free %+v and largest %+v, got %+v and %+v", test.name, test.free, test.largest, free, largest)
+		}
+	}
+}
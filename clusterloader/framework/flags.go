@@ -0,0 +1,53 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"flag"
+	"time"
+)
+
+var (
+	confirmLargeCluster bool
+	kubeAPIQPS          float64
+	kubeAPIBurst        int
+	kubeAPIClients      int
+	objectRetries       int
+	objectRetryBackoff  time.Duration
+	namespacePrefix     string
+	fixedNamespaces     bool
+	contentCacheDir     string
+	randomSeed          int64
+	progressInterval    time.Duration
+	statusAddress       string
+)
+
+// RegisterFlags registers the Cluster Loader specific flags, it must be called before the flags are parsed
+func RegisterFlags() {
+	flag.BoolVar(&confirmLargeCluster, "confirm-large-cluster", false, "Run against clusters with more nodes than preflight.maxnodes without asking for confirmation.")
+	flag.Float64Var(&kubeAPIQPS, "kube-api-qps", 20, "QPS limit of every client creating objects.")
+	flag.IntVar(&kubeAPIBurst, "kube-api-burst", 50, "Burst limit of every client creating objects.")
+	flag.IntVar(&kubeAPIClients, "kube-api-clients", 1, "Number of clients the object operations are spread over, the effective QPS limit is kube-api-qps times this.")
+	flag.IntVar(&objectRetries, "object-retries", maxRetries, "Number of attempts to create an object when it fails with a retryable error (throttling, timeouts, conflicts).")
+	flag.DurationVar(&objectRetryBackoff, "object-retry-backoff", 500*time.Millisecond, "Backoff before the first retry of an object operation, it doubles with every retry.")
+	flag.StringVar(&namespacePrefix, "namespace-prefix", "e2e-tests", "Prefix of the names of the namespaces created by the test, e.g. to identify the team or test in shared clusters.")
+	flag.DurationVar(&progressInterval, "progress-interval", 30*time.Second, "How often the progress of the object creation is logged, 0 disables the logging.")
+	flag.StringVar(&statusAddress, "status-address", "", "Address the progress is served on as JSON under /status and the metrics of the process under /metrics, e.g. :8080. Disabled when empty.")
+	flag.Int64Var(&randomSeed, "random-seed", 0, "Seed of the object counts sampled from min and max, 0 picks a new seed which is logged. Reuse it to reproduce a run.")
+	flag.StringVar(&contentCacheDir, "content-cache-dir", "", "Directory http(s) templates and pod files are cached in, reused across runs. Defaults to a temporary directory per run.")
+	flag.BoolVar(&fixedNamespaces, "deterministic-namespaces", false, "Name the namespaces <namespace-prefix>-<basename> instead of appending a random suffix, so runs are reproducible.")
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+const grafanaTimeout = 10 * time.Second
+
+// grafanaAnnotation is a region annotation of the Grafana HTTP API
+type grafanaAnnotation struct {
+	Time     int64    `json:"time"`
+	TimeEnd  int64    `json:"timeEnd"`
+	IsRegion bool     `json:"isRegion"`
+	Tags     []string `json:"tags"`
+	Text     string   `json:"text"`
+}
+
+// GrafanaAnnotator returns a span observer which posts every phase of the run as a region
+// annotation to Grafana, or nil if no Grafana is configured. Failing to post an annotation
+// is logged and doesn't fail the run.
+func GrafanaAnnotator(config GrafanaConfig) func(Span) {
+	if config.URL == "" {
+		return nil
+	}
+	client := &http.Client{Timeout: grafanaTimeout}
+	url := strings.TrimSuffix(config.URL, "/") + "/api/annotations"
+	return func(span Span) {
+		if span.Category != PhaseSpan {
+			return
+		}
+		annotation := grafanaAnnotation{
+			Time:     span.Start.UnixNano() / int64(time.Millisecond),
+			TimeEnd:  span.Start.Add(span.Duration).UnixNano() / int64(time.Millisecond),
+			IsRegion: true,
+			Tags:     append([]string{"clusterloader"}, config.Tags...),
+			Text:     span.Name,
+		}
+		if err := postAnnotation(client, url, config.APIKey, annotation); err != nil {
+			framework.Logf("Failed to post Grafana annotation for %v: %v", span.Name, err)
+		}
+	}
+}
+
+func postAnnotation(client *http.Client, url, apiKey string, annotation grafanaAnnotation) error {
+	body, err := json.Marshal(annotation)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+// NamespaceHooks are run for every namespace of a project
+type NamespaceHooks struct {
+	// PostCreate hooks run right after the namespace is created, before any object is created in it
+	PostCreate []Hook
+	// PreDelete hooks run at the end of the test, before the namespace is deleted
+	PreDelete []Hook
+}
+
+// Hook either applies a template file from the content directory in the namespace,
+// or executes a shell command with NAMESPACE, KUBECONFIG and KUBECONTEXT set in its environment
+type Hook struct {
+	Template string
+	Exec     string
+}
+
+// RunHooks runs the hooks in order for the namespace and stops at the first failing one
+func RunHooks(hooks []Hook, ns *v1.Namespace) error {
+	for _, hook := range hooks {
+		if err := hook.run(ns); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (hook Hook) run(ns *v1.Namespace) error {
+	switch {
+	case hook.Template != "" && hook.Exec != "":
+		return errors.New("hook can't have both template and exec defined")
+	case hook.Template != "":
+		if _, err := framework.RunKubectl("create", "-f", MakePath(hook.Template), fmt.Sprintf("--namespace=%v", ns.Name)); err != nil {
+			return fmt.Errorf("hook template %v failed in %v: %v", hook.Template, ns.Name, err)
+		}
+		framework.Logf("Applied hook template %v in namespace %v", hook.Template, ns.Name)
+	case hook.Exec != "":
+		if err := runExec(hook.Exec, "NAMESPACE="+ns.Name); err != nil {
+			return fmt.Errorf("hook failed in %v: %v", ns.Name, err)
+		}
+	default:
+		return errors.New("hook has neither template nor exec defined")
+	}
+	return nil
+}
+
+// ProjectExec are shell commands run before and after all namespaces of a project are set up,
+// with PROJECT, REPORT_DIR, NAMESPACE_PREFIX, KUBECONFIG and KUBECONTEXT set in their environment
+type ProjectExec struct {
+	Before []string
+	After  []string
+}
+
+// RunProjectExec runs the commands in order for the project and stops at the first failing one
+func RunProjectExec(commands []string, project string) error {
+	for _, command := range commands {
+		if err := runExec(command, "PROJECT="+project, "REPORT_DIR="+framework.TestContext.ReportDir, "NAMESPACE_PREFIX="+namespacePrefix); err != nil {
+			return fmt.Errorf("project %v: %v", project, err)
+		}
+	}
+	return nil
+}
+
+// runExec runs the shell command with KUBECONFIG, KUBECONTEXT and env added to the environment and logs
+// its output. KUBECONTEXT is the --context of the test, empty for the current context of the kubeconfig.
+func runExec(command string, env ...string) error {
+	cmd := exec.Command("/bin/sh", "-c", command)
+	cmd.Env = append(os.Environ(), "KUBECONFIG="+framework.TestContext.KubeConfig, "KUBECONTEXT="+framework.TestContext.KubeContext)
+	cmd.Env = append(cmd.Env, env...)
+	output, err := cmd.CombinedOutput()
+	framework.Logf("Command %q with %v output:\n%s", command, env, output)
+	if err != nil {
+		return fmt.Errorf("command %q failed: %v", command, err)
+	}
+	return nil
+}
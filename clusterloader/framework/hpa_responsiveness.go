@@ -0,0 +1,300 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kubernetes/pkg/api/v1"
+	autoscaling "k8s.io/kubernetes/pkg/apis/autoscaling/v1"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	"k8s.io/kubernetes/test/e2e/framework"
+	"k8s.io/kubernetes/test/e2e/perftype"
+)
+
+const (
+	// hpaTolerance mirrors the default tolerance of the HPA controller, the utilization has to differ
+	// from the target by more than that before the autoscaler acts
+	hpaTolerance = 0.1
+	// hpaScalePoll is also the resolution of the measured scaling latency
+	hpaScalePoll = time.Second
+)
+
+// hpaResponsivenessMeasurement records how long HPAs take to react to a utilization change
+// and how long the following scale-up or scale-down takes to complete
+type hpaResponsivenessMeasurement struct {
+	c      clientset.Interface
+	lock   sync.Mutex
+	hpas   map[string]*hpaState
+	scales []hpaScale
+	stopCh chan struct{}
+}
+
+type hpaState struct {
+	desiredReplicas int32
+	// metricChanged is when the utilization was first seen out of target tolerance
+	metricChanged time.Time
+	// pending is the scale that has been decided but not completed yet, a new decision replaces it
+	pending *hpaScale
+}
+
+type hpaScale struct {
+	namespace string
+	name      string
+	up        bool
+	reaction  time.Duration
+	// reactionKnown is set when the utilization change was observed, scales caused by spec changes have no reaction
+	reactionKnown bool
+	decided       time.Time
+	completed     time.Time
+}
+
+// Start watches HPAs in all namespaces
+func (m *hpaResponsivenessMeasurement) Start(c clientset.Interface) error {
+	m.c = c
+	m.hpas = make(map[string]*hpaState)
+	m.stopCh = startInformer(&cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return c.Autoscaling().HorizontalPodAutoscalers(metav1.NamespaceAll).List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return c.Autoscaling().HorizontalPodAutoscalers(metav1.NamespaceAll).Watch(options)
+		},
+	}, &autoscaling.HorizontalPodAutoscaler{}, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			m.update(obj.(*autoscaling.HorizontalPodAutoscaler))
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			m.update(obj.(*autoscaling.HorizontalPodAutoscaler))
+		},
+	})
+	return nil
+}
+
+func (m *hpaResponsivenessMeasurement) update(hpa *autoscaling.HorizontalPodAutoscaler) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	now := time.Now()
+	key := hpa.Namespace + "/" + hpa.Name
+	state, ok := m.hpas[key]
+	if !ok {
+		state = &hpaState{desiredReplicas: hpa.Status.DesiredReplicas}
+		m.hpas[key] = state
+	}
+
+	if state.metricChanged.IsZero() && outOfTarget(hpa) {
+		state.metricChanged = now
+	}
+	// HPAs start without a desired replica count, the first one written by the controller isn't a scale
+	if state.desiredReplicas == 0 {
+		state.desiredReplicas = hpa.Status.DesiredReplicas
+	}
+	if hpa.Status.DesiredReplicas != state.desiredReplicas {
+		scale := &hpaScale{
+			namespace: hpa.Namespace,
+			name:      hpa.Name,
+			up:        hpa.Status.DesiredReplicas > state.desiredReplicas,
+			decided:   now,
+		}
+		if !state.metricChanged.IsZero() {
+			scale.reaction = now.Sub(state.metricChanged)
+			scale.reactionKnown = true
+		}
+		state.desiredReplicas = hpa.Status.DesiredReplicas
+		state.metricChanged = time.Time{}
+		state.pending = scale
+		go m.waitForScale(state, scale, hpa.Spec.ScaleTargetRef, hpa.Status.DesiredReplicas)
+	}
+}
+
+// waitForScale records the scale once all replicas of the target are ready. The current replicas
+// in the HPA status are only refreshed on the sync period of the controller, so the target is
+// checked instead.
+func (m *hpaResponsivenessMeasurement) waitForScale(state *hpaState, scale *hpaScale, target autoscaling.CrossVersionObjectReference, replicas int32) {
+	err := wait.PollUntil(hpaScalePoll, func() (bool, error) {
+		m.lock.Lock()
+		superseded := state.pending != scale
+		m.lock.Unlock()
+		if superseded {
+			return true, nil
+		}
+		return m.targetScaled(scale.namespace, target, replicas)
+	}, m.stopCh)
+	if err != nil {
+		if err != wait.ErrWaitTimeout {
+			framework.Logf("Error waiting for the scale of HPA %v/%v: %v", scale.namespace, scale.name, err)
+		}
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if state.pending != scale {
+		return
+	}
+	scale.completed = time.Now()
+	m.scales = append(m.scales, *scale)
+	state.pending = nil
+}
+
+// targetScaled checks whether the scale target has exactly the replicas and all of them are ready
+func (m *hpaResponsivenessMeasurement) targetScaled(namespace string, target autoscaling.CrossVersionObjectReference, replicas int32) (bool, error) {
+	var current, ready int32
+	switch target.Kind {
+	case "ReplicationController":
+		rc, err := m.c.Core().ReplicationControllers(namespace).Get(target.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		current, ready = rc.Status.Replicas, rc.Status.ReadyReplicas
+	case "Deployment":
+		deployment, err := m.c.Extensions().Deployments(namespace).Get(target.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		current, ready = deployment.Status.Replicas, deployment.Status.ReadyReplicas
+	case "ReplicaSet":
+		rs, err := m.c.Extensions().ReplicaSets(namespace).Get(target.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		current, ready = rs.Status.Replicas, rs.Status.ReadyReplicas
+	default:
+		return false, fmt.Errorf("unsupported scale target kind %v", target.Kind)
+	}
+	return current == replicas && ready == replicas, nil
+}
+
+// outOfTarget checks whether the HPA should change the replica count based on its current utilization
+func outOfTarget(hpa *autoscaling.HorizontalPodAutoscaler) bool {
+	if hpa.Spec.TargetCPUUtilizationPercentage == nil || hpa.Status.CurrentCPUUtilizationPercentage == nil {
+		return false
+	}
+	ratio := float64(*hpa.Status.CurrentCPUUtilizationPercentage) / float64(*hpa.Spec.TargetCPUUtilizationPercentage)
+	if ratio > 1+hpaTolerance {
+		return hpa.Status.CurrentReplicas < hpa.Spec.MaxReplicas
+	}
+	if ratio < 1-hpaTolerance {
+		minReplicas := int32(1)
+		if hpa.Spec.MinReplicas != nil {
+			minReplicas = *hpa.Spec.MinReplicas
+		}
+		return hpa.Status.CurrentReplicas > minReplicas
+	}
+	return false
+}
+
+// Stop summarizes the scales of HPAs in the namespaces
+func (m *hpaResponsivenessMeasurement) Stop(namespaces []*v1.Namespace) (framework.TestDataSummary, error) {
+	close(m.stopCh)
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	nsSet := namespaceSet(namespaces)
+	var upReaction, upScaling, downReaction, downScaling []framework.PodLatencyData
+	for _, scale := range m.scales {
+		if !nsSet[scale.namespace] {
+			continue
+		}
+		reaction := framework.PodLatencyData{Name: scale.name, Latency: scale.reaction}
+		scaling := framework.PodLatencyData{Name: scale.name, Latency: scale.completed.Sub(scale.decided)}
+		if scale.up {
+			if scale.reactionKnown {
+				upReaction = append(upReaction, reaction)
+			}
+			upScaling = append(upScaling, scaling)
+		} else {
+			if scale.reactionKnown {
+				downReaction = append(downReaction, reaction)
+			}
+			downScaling = append(downScaling, scaling)
+		}
+	}
+	return &HPAResponsivenessSummary{
+		ScaleUp: HPAScaleLatency{
+			Count:    len(upScaling),
+			Reaction: latencyMetric(upReaction),
+			Scaling:  latencyMetric(upScaling),
+		},
+		ScaleDown: HPAScaleLatency{
+			Count:    len(downScaling),
+			Reaction: latencyMetric(downReaction),
+			Scaling:  latencyMetric(downScaling),
+		},
+	}, nil
+}
+
+// HPAResponsivenessSummary holds HPA latencies split by the scaling direction
+type HPAResponsivenessSummary struct {
+	ScaleUp   HPAScaleLatency `json:"scaleUp"`
+	ScaleDown HPAScaleLatency `json:"scaleDown"`
+}
+
+// HPAScaleLatency holds the time to react to a utilization change and the time until the scale
+// target has the new replica count, all of them ready
+type HPAScaleLatency struct {
+	Count    int                     `json:"count"`
+	Reaction framework.LatencyMetric `json:"reaction"`
+	Scaling  framework.LatencyMetric `json:"scaling"`
+}
+
+// SummaryKind returns the name of the summary
+func (s *HPAResponsivenessSummary) SummaryKind() string {
+	return "HPAResponsiveness"
+}
+
+// PrintHumanReadable prints the summary as a table
+func (s *HPAResponsivenessSummary) PrintHumanReadable() string {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 1, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "Direction\tCount\tReaction50\tReaction99\tScaling50\tScaling99\n")
+	for _, d := range []struct {
+		name    string
+		latency HPAScaleLatency
+	}{{"up", s.ScaleUp}, {"down", s.ScaleDown}} {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\n", d.name, d.latency.Count, d.latency.Reaction.Perc50,
+			d.latency.Reaction.Perc99, d.latency.Scaling.Perc50, d.latency.Scaling.Perc99)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// PrintJSON prints the summary as json
+func (s *HPAResponsivenessSummary) PrintJSON() string {
+	return framework.PrettyPrintJSON(s)
+}
+
+// PerfData converts the summary into perfdash data items, one per scale direction and metric
+func (s *HPAResponsivenessSummary) PerfData() *perftype.PerfData {
+	return &perftype.PerfData{
+		Version: currentPerfDataVersion,
+		DataItems: []perftype.DataItem{
+			latencyToDataItem(s.ScaleUp.Reaction, map[string]string{"Metric": "reaction", "Direction": "up"}),
+			latencyToDataItem(s.ScaleUp.Scaling, map[string]string{"Metric": "scaling", "Direction": "up"}),
+			latencyToDataItem(s.ScaleDown.Reaction, map[string]string{"Metric": "reaction", "Direction": "down"}),
+			latencyToDataItem(s.ScaleDown.Scaling, map[string]string{"Metric": "scaling", "Direction": "down"}),
+		},
+	}
+}
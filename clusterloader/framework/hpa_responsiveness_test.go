@@ -0,0 +1,64 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"testing"
+
+	autoscaling "k8s.io/kubernetes/pkg/apis/autoscaling/v1"
+)
+
+func newTestHPA(target, current *int32, minReplicas *int32, currentReplicas, maxReplicas int32) *autoscaling.HorizontalPodAutoscaler {
+	return &autoscaling.HorizontalPodAutoscaler{
+		Spec: autoscaling.HorizontalPodAutoscalerSpec{
+			MinReplicas:                    minReplicas,
+			MaxReplicas:                    maxReplicas,
+			TargetCPUUtilizationPercentage: target,
+		},
+		Status: autoscaling.HorizontalPodAutoscalerStatus{
+			CurrentReplicas:                 currentReplicas,
+			CurrentCPUUtilizationPercentage: current,
+		},
+	}
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func TestOutOfTarget(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		hpa      *autoscaling.HorizontalPodAutoscaler
+		expected bool
+	}{
+		{"no target", newTestHPA(nil, int32Ptr(90), nil, 2, 10), false},
+		{"no utilization yet", newTestHPA(int32Ptr(50), nil, nil, 2, 10), false},
+		{"within tolerance above", newTestHPA(int32Ptr(50), int32Ptr(55), nil, 2, 10), false},
+		{"within tolerance below", newTestHPA(int32Ptr(50), int32Ptr(45), nil, 2, 10), false},
+		{"above", newTestHPA(int32Ptr(50), int32Ptr(80), nil, 2, 10), true},
+		{"above at max replicas", newTestHPA(int32Ptr(50), int32Ptr(80), nil, 10, 10), false},
+		{"below", newTestHPA(int32Ptr(50), int32Ptr(10), nil, 2, 10), true},
+		{"below at default min replicas", newTestHPA(int32Ptr(50), int32Ptr(10), nil, 1, 10), false},
+		{"below at min replicas", newTestHPA(int32Ptr(50), int32Ptr(10), int32Ptr(3), 3, 10), false},
+		{"below above min replicas", newTestHPA(int32Ptr(50), int32Ptr(10), int32Ptr(3), 4, 10), true},
+	} {
+		if out := outOfTarget(test.hpa); out != test.expected {
+			t.Errorf("%v: expected outOfTarget %v, got %v", test.name, test.expected, out)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kubernetes/pkg/api/v1"
+	batch "k8s.io/kubernetes/pkg/apis/batch/v1"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	"k8s.io/kubernetes/test/e2e/framework"
+	"k8s.io/kubernetes/test/e2e/perftype"
+)
+
+// jobNameLabel is set by the job controller on the pods it creates
+const jobNameLabel = "job-name"
+
+// jobThroughputMeasurement records the creation to completion latency of Jobs and how many
+// Jobs and Job pods complete per second
+type jobThroughputMeasurement struct {
+	lock sync.Mutex
+	jobs map[string]*jobState
+	// podCompletions holds the time the last container of every succeeded Job pod finished, by namespace/name
+	podCompletions map[string]podCompletion
+	stopChs        []chan struct{}
+}
+
+type jobState struct {
+	namespace string
+	created   time.Time
+	completed time.Time
+	failed    bool
+}
+
+type podCompletion struct {
+	namespace string
+	finished  time.Time
+}
+
+// Start watches Jobs and the pods created for them in all namespaces
+func (m *jobThroughputMeasurement) Start(c clientset.Interface) error {
+	m.jobs = make(map[string]*jobState)
+	m.podCompletions = make(map[string]podCompletion)
+	m.stopChs = append(m.stopChs, startInformer(&cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return c.Batch().Jobs(metav1.NamespaceAll).List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return c.Batch().Jobs(metav1.NamespaceAll).Watch(options)
+		},
+	}, &batch.Job{}, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			m.updateJob(obj.(*batch.Job))
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			m.updateJob(obj.(*batch.Job))
+		},
+	}))
+	m.stopChs = append(m.stopChs, startInformer(&cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.LabelSelector = jobNameLabel
+			return c.Core().Pods(metav1.NamespaceAll).List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.LabelSelector = jobNameLabel
+			return c.Core().Pods(metav1.NamespaceAll).Watch(options)
+		},
+	}, &v1.Pod{}, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			m.updatePod(obj.(*v1.Pod))
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			m.updatePod(obj.(*v1.Pod))
+		},
+	}))
+	return nil
+}
+
+func (m *jobThroughputMeasurement) updateJob(job *batch.Job) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	key := job.Namespace + "/" + job.Name
+	state, ok := m.jobs[key]
+	if !ok {
+		state = &jobState{namespace: job.Namespace, created: job.CreationTimestamp.Time}
+		m.jobs[key] = state
+	}
+	if !state.completed.IsZero() {
+		return
+	}
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != v1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batch.JobComplete:
+			state.completed = condition.LastTransitionTime.Time
+			if job.Status.CompletionTime != nil {
+				state.completed = job.Status.CompletionTime.Time
+			}
+		case batch.JobFailed:
+			state.completed = condition.LastTransitionTime.Time
+			state.failed = true
+		}
+	}
+}
+
+func (m *jobThroughputMeasurement) updatePod(pod *v1.Pod) {
+	if pod.Status.Phase != v1.PodSucceeded {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	key := pod.Namespace + "/" + pod.Name
+	if _, ok := m.podCompletions[key]; ok {
+		return
+	}
+	completion := podCompletion{namespace: pod.Namespace}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Terminated != nil && status.State.Terminated.FinishedAt.After(completion.finished) {
+			completion.finished = status.State.Terminated.FinishedAt.Time
+		}
+	}
+	if completion.finished.IsZero() {
+		completion.finished = time.Now()
+	}
+	m.podCompletions[key] = completion
+}
+
+// Stop summarizes the Jobs and Job pods in the namespaces
+func (m *jobThroughputMeasurement) Stop(namespaces []*v1.Namespace) (framework.TestDataSummary, error) {
+	for _, stopCh := range m.stopChs {
+		close(stopCh)
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	nsSet := namespaceSet(namespaces)
+	summary := &JobThroughputSummary{}
+	var latencies []framework.PodLatencyData
+	var first, lastJob, lastPod time.Time
+	for key, job := range m.jobs {
+		if !nsSet[job.namespace] {
+			continue
+		}
+		summary.Jobs++
+		if first.IsZero() || job.created.Before(first) {
+			first = job.created
+		}
+		switch {
+		case job.completed.IsZero():
+			continue
+		case job.failed:
+			summary.FailedJobs++
+			continue
+		}
+		summary.CompletedJobs++
+		latencies = append(latencies, framework.PodLatencyData{Name: key, Latency: job.completed.Sub(job.created)})
+		if job.completed.After(lastJob) {
+			lastJob = job.completed
+		}
+	}
+	for _, completion := range m.podCompletions {
+		if !nsSet[completion.namespace] {
+			continue
+		}
+		summary.PodCompletions++
+		if completion.finished.After(lastPod) {
+			lastPod = completion.finished
+		}
+	}
+	summary.CreationToCompletion = latencyMetric(latencies)
+	if lastJob.After(first) {
+		summary.JobsPerSecond = float64(summary.CompletedJobs) / lastJob.Sub(first).Seconds()
+	}
+	if lastPod.After(first) {
+		summary.PodCompletionsPerSecond = float64(summary.PodCompletions) / lastPod.Sub(first).Seconds()
+	}
+	return summary, nil
+}
+
+// JobThroughputSummary holds the creation to completion latency of the Jobs in the Cluster Loader namespaces
+// and the completion rates from the creation of the first Job until the last completion
+type JobThroughputSummary struct {
+	Jobs                    int                     `json:"jobs"`
+	CompletedJobs           int                     `json:"completedJobs"`
+	FailedJobs              int                     `json:"failedJobs"`
+	PodCompletions          int                     `json:"podCompletions"`
+	CreationToCompletion    framework.LatencyMetric `json:"creationToCompletion"`
+	JobsPerSecond           float64                 `json:"jobsPerSecond"`
+	PodCompletionsPerSecond float64                 `json:"podCompletionsPerSecond"`
+}
+
+// SummaryKind returns the name of the summary
+func (s *JobThroughputSummary) SummaryKind() string {
+	return "JobThroughput"
+}
+
+// PrintHumanReadable prints the summary as a table
+func (s *JobThroughputSummary) PrintHumanReadable() string {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 1, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "Jobs: %v, completed: %v, failed: %v, pod completions: %v\n", s.Jobs, s.CompletedJobs, s.FailedJobs, s.PodCompletions)
+	fmt.Fprintf(w, "Jobs/s: %.2f, pod completions/s: %.2f\n", s.JobsPerSecond, s.PodCompletionsPerSecond)
+	fmt.Fprintf(w, "Latency\tPerc50\tPerc90\tPerc99\tPerc100\n")
+	fmt.Fprintf(w, "creation_to_completion\t%v\t%v\t%v\t%v\n", s.CreationToCompletion.Perc50, s.CreationToCompletion.Perc90, s.CreationToCompletion.Perc99, s.CreationToCompletion.Perc100)
+	w.Flush()
+	return buf.String()
+}
+
+// PrintJSON prints the summary as json
+func (s *JobThroughputSummary) PrintJSON() string {
+	return framework.PrettyPrintJSON(s)
+}
+
+// PerfData converts the latency and the completion rates into perfdash data items
+func (s *JobThroughputSummary) PerfData() *perftype.PerfData {
+	return &perftype.PerfData{
+		Version: currentPerfDataVersion,
+		DataItems: []perftype.DataItem{
+			latencyToDataItem(s.CreationToCompletion, map[string]string{"Metric": "creation_to_completion"}),
+			{
+				Data: map[string]float64{
+					"Jobs":           s.JobsPerSecond,
+					"PodCompletions": s.PodCompletionsPerSecond,
+				},
+				Unit:   "1/s",
+				Labels: map[string]string{"Metric": "throughput"},
+			},
+		},
+	}
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api/v1"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	"k8s.io/kubernetes/test/e2e/framework"
+	"k8s.io/kubernetes/test/e2e/perftype"
+)
+
+const (
+	defaultListInterval = 10 * time.Second
+	// apiserverMemoryMetric is the resident memory of the apiserver process in its metrics
+	apiserverMemoryMetric = "process_resident_memory_bytes"
+)
+
+// listLatencyMeasurement issues LIST calls of a core resource every interval and records their
+// latency, the size of the responses and the memory of the apiserver serving them
+type listLatencyMeasurement struct {
+	resource        string
+	namespace       string
+	resourceVersion string
+	interval        time.Duration
+	lock            sync.Mutex
+	summary         ListLatencySummary
+	latencies       []framework.PodLatencyData
+	stopCh          chan struct{}
+	doneCh          chan struct{}
+}
+
+func newListLatencyMeasurement(config MeasurementConfig) (*listLatencyMeasurement, error) {
+	m := &listLatencyMeasurement{
+		resource:        "pods",
+		namespace:       config.Params["namespace"],
+		resourceVersion: config.Params["resourceversion"],
+		interval:        defaultListInterval,
+	}
+	if resource, ok := config.Params["resource"]; ok {
+		m.resource = strings.ToLower(resource)
+	}
+	if interval, ok := config.Params["interval"]; ok {
+		duration, err := time.ParseDuration(interval)
+		if err != nil {
+			return nil, err
+		}
+		if duration <= 0 {
+			return nil, fmt.Errorf("interval must be positive, got %v", duration)
+		}
+		m.interval = duration
+	}
+	return m, nil
+}
+
+// Start lists the resource every interval until Stop is called
+func (m *listLatencyMeasurement) Start(c clientset.Interface) error {
+	m.summary = ListLatencySummary{Resource: m.resource, Namespace: m.namespace, ResourceVersion: m.resourceVersion}
+	memory, err := apiserverMemory(c)
+	if err != nil {
+		framework.Logf("Failed to read the apiserver memory: %v", err)
+	}
+	m.summary.APIServerMemoryStart = memory
+	m.summary.APIServerMemoryMax = memory
+	m.stopCh = make(chan struct{})
+	m.doneCh = make(chan struct{})
+	go func() {
+		defer close(m.doneCh)
+		for {
+			m.list(c)
+			select {
+			case <-m.stopCh:
+				return
+			case <-time.After(m.interval):
+			}
+		}
+	}()
+	return nil
+}
+
+func (m *listLatencyMeasurement) list(c clientset.Interface) {
+	request := c.Core().RESTClient().Get().Namespace(m.namespace).Resource(m.resource)
+	if m.resourceVersion != "" {
+		request = request.Param("resourceVersion", m.resourceVersion)
+	}
+	start := time.Now()
+	body, err := request.DoRaw()
+	latency := time.Since(start)
+	memory, memoryErr := apiserverMemory(c)
+	if memoryErr != nil {
+		framework.Logf("Failed to read the apiserver memory: %v", memoryErr)
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.summary.Calls++
+	if memory > m.summary.APIServerMemoryMax {
+		m.summary.APIServerMemoryMax = memory
+	}
+	if err != nil {
+		framework.Logf("Failed to list %v: %v", m.resource, err)
+		m.summary.Errors++
+		return
+	}
+	if len(body) > m.summary.MaxResponseBytes {
+		m.summary.MaxResponseBytes = len(body)
+	}
+	m.latencies = append(m.latencies, framework.PodLatencyData{Name: m.resource, Latency: latency})
+}
+
+// apiserverMemory reads the resident memory from the metrics of the apiserver answering the request
+func apiserverMemory(c clientset.Interface) (int64, error) {
+	body, err := c.Core().RESTClient().Get().AbsPath("/metrics").DoRaw()
+	if err != nil {
+		return 0, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == apiserverMemoryMetric {
+			value, err := strconv.ParseFloat(fields[1], 64)
+			if err != nil {
+				return 0, err
+			}
+			return int64(value), nil
+		}
+	}
+	return 0, fmt.Errorf("%v not found in the apiserver metrics", apiserverMemoryMetric)
+}
+
+// Stop waits for the running LIST call and summarizes all calls, regardless of the namespaces
+func (m *listLatencyMeasurement) Stop(_ []*v1.Namespace) (framework.TestDataSummary, error) {
+	close(m.stopCh)
+	<-m.doneCh
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	summary := m.summary
+	summary.Latency = latencyMetric(m.latencies)
+	return &summary, nil
+}
+
+// ListLatencySummary holds the latency of the LIST calls and the apiserver memory while they were issued
+type ListLatencySummary struct {
+	Resource             string                  `json:"resource"`
+	Namespace            string                  `json:"namespace"`
+	ResourceVersion      string                  `json:"resourceVersion"`
+	Calls                int                     `json:"calls"`
+	Errors               int                     `json:"errors"`
+	MaxResponseBytes     int                     `json:"maxResponseBytes"`
+	Latency              framework.LatencyMetric `json:"latency"`
+	APIServerMemoryStart int64                   `json:"apiserverMemoryStart"`
+	APIServerMemoryMax   int64                   `json:"apiserverMemoryMax"`
+}
+
+// SummaryKind returns the name of the summary
+func (s *ListLatencySummary) SummaryKind() string {
+	return "ListLatency"
+}
+
+// PrintHumanReadable prints the summary as a table
+func (s *ListLatencySummary) PrintHumanReadable() string {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 1, 0, 1, ' ', 0)
+	scope := s.Namespace
+	if scope == "" {
+		scope = "all namespaces"
+	}
+	fmt.Fprintf(w, "LIST %v in %v (resourceVersion %q): %v calls, %v errors, largest response %v bytes\n", s.Resource, scope, s.ResourceVersion, s.Calls, s.Errors, s.MaxResponseBytes)
+	fmt.Fprintf(w, "Apiserver memory: %v bytes at start, %v bytes max\n", s.APIServerMemoryStart, s.APIServerMemoryMax)
+	fmt.Fprintf(w, "Latency\tPerc50\tPerc90\tPerc99\tPerc100\n")
+	fmt.Fprintf(w, "list\t%v\t%v\t%v\t%v\n", s.Latency.Perc50, s.Latency.Perc90, s.Latency.Perc99, s.Latency.Perc100)
+	w.Flush()
+	return buf.String()
+}
+
+// PrintJSON prints the summary as json
+func (s *ListLatencySummary) PrintJSON() string {
+	return framework.PrettyPrintJSON(s)
+}
+
+// PerfData converts the LIST latency and the apiserver memory into perfdash data items
+func (s *ListLatencySummary) PerfData() *perftype.PerfData {
+	labels := map[string]string{"Metric": "list", "Resource": s.Resource}
+	return &perftype.PerfData{
+		Version: currentPerfDataVersion,
+		DataItems: []perftype.DataItem{
+			latencyToDataItem(s.Latency, labels),
+			{
+				Data: map[string]float64{
+					"Start": float64(s.APIServerMemoryStart) / (1024 * 1024),
+					"Max":   float64(s.APIServerMemoryMax) / (1024 * 1024),
+				},
+				Unit:   "MiB",
+				Labels: map[string]string{"Metric": "apiserver_memory", "Resource": s.Resource},
+			},
+		},
+	}
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kubernetes/pkg/api/v1"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+// Measurement gathers data from the cluster while the Cluster Loader projects are created
+type Measurement interface {
+	// Start begins collecting data, it is called before any project is created
+	Start(c clientset.Interface) error
+	// Stop finishes collecting data and summarizes what was gathered for the given namespaces
+	Stop(namespaces []*v1.Namespace) (framework.TestDataSummary, error)
+}
+
+// measurementParams are the params every measurement understands, others are most likely typos
+var measurementParams = map[string][]string{
+	"pvlatency":            nil,
+	"hparesponsiveness":    nil,
+	"poddistribution":      nil,
+	"autoscalerlatency":    {"pods", "cpu", "memory"},
+	"nodeutilization":      {"interval"},
+	"eventcounts":          {"spikethreshold"},
+	"objectconditions":     {"group", "version", "resource", "conditiontype", "conditionstatus", "count", "namespaceprefix", "timeout"},
+	"controlplanerestarts": {"selector", "failonrestart"},
+	"nodereadiness":        nil,
+	"configpropagation":    {"kind", "samples", "image", "timeout"},
+	"jobthroughput":        nil,
+	"watchlatency":         {"watchers"},
+	"listlatency":          {"resource", "namespace", "resourceversion", "interval"},
+	"auditlog":             {"path", "top", "hotqps"},
+}
+
+// NewMeasurement returns the measurement matching the name from the config
+func NewMeasurement(config MeasurementConfig) (Measurement, error) {
+	if known, ok := measurementParams[strings.ToLower(config.Name)]; ok {
+		if err := checkParams(config, known); err != nil {
+			return nil, err
+		}
+	}
+	switch strings.ToLower(config.Name) {
+	case "pvlatency":
+		return &pvLatencyMeasurement{}, nil
+	case "hparesponsiveness":
+		return &hpaResponsivenessMeasurement{}, nil
+	case "poddistribution":
+		return &podDistributionMeasurement{}, nil
+	case "autoscalerlatency":
+		return newAutoscalerLatencyMeasurement(config)
+	case "nodeutilization":
+		return newNodeUtilizationMeasurement(config)
+	case "eventcounts":
+		return newEventCountsMeasurement(config)
+	case "objectconditions":
+		return newObjectConditionsMeasurement(config)
+	case "controlplanerestarts":
+		return newControlPlaneRestartsMeasurement(config)
+	case "nodereadiness":
+		return &nodeReadinessMeasurement{}, nil
+	case "configpropagation":
+		return newConfigPropagationMeasurement(config)
+	case "jobthroughput":
+		return &jobThroughputMeasurement{}, nil
+	case "watchlatency":
+		return newWatchLatencyMeasurement(config)
+	case "listlatency":
+		return newListLatencyMeasurement(config)
+	case "auditlog":
+		return newAuditLogMeasurement(config)
+	}
+	return nil, fmt.Errorf("unknown measurement %q", config.Name)
+}
+
+// checkParams returns an error for the first param of the config which isn't known
+func checkParams(config MeasurementConfig, known []string) error {
+	var names []string
+	for name := range config.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		found := false
+		for _, k := range known {
+			if strings.ToLower(name) == k {
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("measurement %q has unknown param %q, known params: %v", config.Name, name, known)
+		}
+	}
+	return nil
+}
+
+// outputPrintTypes are the --output-print-type values of PrintSummary, including the ones the
+// framework doesn't know
+var outputPrintTypes []string
+
+// SetupOutputPrintTypes removes perfdash, which only Cluster Loader knows, from the
+// --output-print-type the framework uses for its own summaries, so it doesn't log it as unknown
+func SetupOutputPrintTypes() {
+	if outputPrintTypes != nil {
+		return
+	}
+	outputPrintTypes = strings.Split(framework.TestContext.OutputPrintType, ",")
+	var frameworkTypes []string
+	for _, printType := range outputPrintTypes {
+		if printType != "perfdash" {
+			frameworkTypes = append(frameworkTypes, printType)
+		}
+	}
+	framework.TestContext.OutputPrintType = strings.Join(frameworkTypes, ",")
+}
+
+// PrintSummary outputs the summary the same way the e2e framework outputs its own summaries
+func PrintSummary(summary framework.TestDataSummary) {
+	now := time.Now()
+	printTypes := outputPrintTypes
+	if printTypes == nil {
+		printTypes = strings.Split(framework.TestContext.OutputPrintType, ",")
+	}
+	for _, printType := range printTypes {
+		switch printType {
+		case "hr":
+			if framework.TestContext.ReportDir == "" {
+				framework.Logf(summary.PrintHumanReadable())
+				continue
+			}
+			filePath := path.Join(framework.TestContext.ReportDir, summary.SummaryKind()+now.Format(time.RFC3339)+".txt")
+			if err := ioutil.WriteFile(filePath, []byte(summary.PrintHumanReadable()), 0644); err != nil {
+				framework.Logf("Failed to write file %v with test performance data: %v", filePath, err)
+			}
+		case "json":
+			if framework.TestContext.ReportDir == "" {
+				framework.Logf("%v JSON\n%v", summary.SummaryKind(), summary.PrintJSON())
+				continue
+			}
+			filePath := path.Join(framework.TestContext.ReportDir, summary.SummaryKind()+now.Format(time.RFC3339)+".json")
+			if err := ioutil.WriteFile(filePath, []byte(summary.PrintJSON()), 0644); err != nil {
+				framework.Logf("Failed to write file %v with test performance data: %v", filePath, err)
+			}
+		case "perfdash":
+			perfSummary, ok := summary.(PerfDataSummary)
+			if !ok {
+				continue
+			}
+			if framework.TestContext.ReportDir == "" {
+				framework.PrintPerfData(perfSummary.PerfData())
+				continue
+			}
+			filePath := path.Join(framework.TestContext.ReportDir, summary.SummaryKind()+"_perfdata_"+now.Format(time.RFC3339)+".json")
+			if err := ioutil.WriteFile(filePath, []byte(framework.PrettyPrintJSON(perfSummary.PerfData())), 0644); err != nil {
+				framework.Logf("Failed to write file %v with test performance data: %v", filePath, err)
+			}
+		default:
+			framework.Logf("Unknown output type: %v. Skipping.", printType)
+		}
+	}
+}
+
+// startInformer runs an informer over lw until the returned channel is closed
+func startInformer(lw *cache.ListWatch, objType runtime.Object, handler cache.ResourceEventHandlerFuncs) chan struct{} {
+	stopCh := make(chan struct{})
+	_, controller := cache.NewInformer(lw, objType, 0, handler)
+	go controller.Run(stopCh)
+	return stopCh
+}
+
+// namespaceSet converts the namespaces created by Cluster Loader into a set of names
+func namespaceSet(namespaces []*v1.Namespace) map[string]bool {
+	set := make(map[string]bool, len(namespaces))
+	for _, ns := range namespaces {
+		set[ns.Name] = true
+	}
+	return set
+}
+
+// latencyMetric sorts the latencies and extracts the percentiles, it returns a zero metric for no data
+func latencyMetric(latencies []framework.PodLatencyData) framework.LatencyMetric {
+	if len(latencies) == 0 {
+		return framework.LatencyMetric{}
+	}
+	sort.Sort(framework.LatencySlice(latencies))
+	return framework.ExtractLatencyMetrics(latencies)
+}
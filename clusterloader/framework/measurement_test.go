@@ -0,0 +1,50 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+func TestSetupOutputPrintTypes(t *testing.T) {
+	defer func(printTypes string) {
+		outputPrintTypes = nil
+		framework.TestContext.OutputPrintType = printTypes
+	}(framework.TestContext.OutputPrintType)
+	for _, test := range []struct {
+		printTypes string
+		expected   []string
+		framework  string
+	}{
+		{"hr", []string{"hr"}, "hr"},
+		{"hr,json", []string{"hr", "json"}, "hr,json"},
+		{"json,perfdash", []string{"json", "perfdash"}, "json"},
+		{"perfdash", []string{"perfdash"}, ""},
+	} {
+		outputPrintTypes = nil
+		framework.TestContext.OutputPrintType = test.printTypes
+		SetupOutputPrintTypes()
+		// A second call must not lose the types taken away from the framework
+		SetupOutputPrintTypes()
+		if !reflect.DeepEqual(outputPrintTypes, test.expected) || framework.TestContext.OutputPrintType != test.framework {
+			t.Errorf("%q: expected %v and %q for the framework, got %v and %q", test.printTypes, test.expected, test.framework, outputPrintTypes, framework.TestContext.OutputPrintType)
+		}
+	}
+}
@@ -0,0 +1,352 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/kubernetes/pkg/api/v1"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	"k8s.io/kubernetes/test/e2e/framework"
+	"k8s.io/kubernetes/test/e2e/perftype"
+)
+
+const (
+	namespaceDeletionTimeout = 30 * time.Minute
+	// namespaceDeletionPoll is also the resolution of the measured deletion latency
+	namespaceDeletionPoll = time.Second
+)
+
+var (
+	parallelLock sync.Mutex
+	// managedNamespaces are the namespaces created by Cluster Loader, DeleteNamespaces deletes them
+	// before the framework does so the deletion latency can be measured
+	managedNamespaces []managedNamespace
+	deleteParallelism = 1
+)
+
+// managedNamespace is a namespace created by Cluster Loader with the project tuning which paces its deletion
+type managedNamespace struct {
+	name   string
+	tuning *TuningSetObject
+}
+
+// addManagedNamespace registers a namespace created by Cluster Loader for deletion, tuning may be nil
+func addManagedNamespace(name string, tuning *TuningSetObject) {
+	parallelLock.Lock()
+	defer parallelLock.Unlock()
+	managedNamespaces = append(managedNamespaces, managedNamespace{name: name, tuning: tuning})
+}
+
+// CreateNamespaces creates the namespaces with tuning.Parallelism workers, each waiting for
+// tuning.RateLimit.Delay between namespaces. Namespaces which already exist are reused.
+func CreateNamespaces(f *framework.Framework, names []string, tuning *TuningSetObject) ([]*v1.Namespace, error) {
+	existing := make(map[string]*v1.Namespace)
+	list, err := f.ClientSet.Core().Namespaces().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range list.Items {
+		// Terminating namespaces of a previous run can't be reused
+		if list.Items[i].DeletionTimestamp == nil {
+			existing[list.Items[i].GenerateName] = &list.Items[i]
+		}
+	}
+
+	namespaces := make([]*v1.Namespace, len(names))
+	errs := make([]error, len(names))
+	indexes := make(chan int, len(names))
+	for i := range names {
+		indexes <- i
+	}
+	close(indexes)
+	var created int
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	for w := 0; w < tuning.Parallelism; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if ns, ok := existing[namespaceGenerateName(names[i])]; ok {
+					framework.Logf("Namespace exists %s ", names[i])
+					namespaces[i] = ns
+					continue
+				}
+				namespaces[i], errs[i] = createTestingNS(names[i], f.ClientSet, nil)
+				if errs[i] != nil {
+					continue
+				}
+				addManagedNamespace(namespaces[i].Name, tuning)
+				lock.Lock()
+				created++
+				if created%progressStep(len(names)) == 0 {
+					framework.Logf("Created %d/%d namespaces", created, len(names))
+				}
+				lock.Unlock()
+				if err := tuning.Delay(); err != nil {
+					errs[i] = err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	parallelLock.Lock()
+	if tuning.Parallelism > deleteParallelism {
+		deleteParallelism = tuning.Parallelism
+	}
+	parallelLock.Unlock()
+	var failed []error
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) > 0 {
+		return nil, utilerrors.NewAggregate(failed)
+	}
+	return namespaces, nil
+}
+
+// SetupNamespaceNaming makes the framework name the namespaces it creates after the
+// --namespace-prefix and --deterministic-namespaces flags
+func SetupNamespaceNaming() {
+	framework.TestContext.CreateTestingNS = createTestingNS
+}
+
+// namespaceGenerateName returns the prefix of the names of the namespaces created for baseName
+func namespaceGenerateName(baseName string) string {
+	return fmt.Sprintf("%v-%v-", namespacePrefix, baseName)
+}
+
+// createTestingNS is framework.CreateTestingNS with the configured namespace naming
+func createTestingNS(baseName string, c clientset.Interface, labels map[string]string) (*v1.Namespace, error) {
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels["e2e-run"] = string(framework.RunId)
+
+	namespaceObj := &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: namespaceGenerateName(baseName),
+			Labels:       labels,
+		},
+	}
+	if fixedNamespaces {
+		// GenerateName is still set, it is how namespaces of previous runs are found
+		namespaceObj.Name = fmt.Sprintf("%v-%v", namespacePrefix, baseName)
+		if err := waitForPreviousNamespace(c, namespaceObj.Name); err != nil {
+			return nil, err
+		}
+	}
+	var got *v1.Namespace
+	if err := wait.PollImmediate(framework.Poll, 30*time.Second, func() (bool, error) {
+		var err error
+		got, err = c.Core().Namespaces().Create(namespaceObj)
+		if errors.IsAlreadyExists(err) {
+			return false, err
+		}
+		if err != nil {
+			framework.Logf("Unexpected error while creating namespace: %v", err)
+			return false, nil
+		}
+		return true, nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if framework.TestContext.VerifyServiceAccount {
+		if err := framework.WaitForDefaultServiceAccountInNamespace(c, got.Name); err != nil {
+			return got, err
+		}
+	}
+	return got, nil
+}
+
+// waitForPreviousNamespace waits until the namespace with the deterministic name, left by a
+// previous run, is gone. A namespace which isn't being deleted is an error as its content is unknown.
+func waitForPreviousNamespace(c clientset.Interface, name string) error {
+	ns, err := c.Core().Namespaces().Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if ns.DeletionTimestamp == nil {
+		return fmt.Errorf("namespace %v from a previous run still exists, delete it or run without --deterministic-namespaces", name)
+	}
+	framework.Logf("Namespace %v from a previous run is still terminating, waiting until it is gone", name)
+	err = wait.Poll(namespaceDeletionPoll, namespaceDeletionTimeout, func() (bool, error) {
+		_, err := c.Core().Namespaces().Get(name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		if err != nil {
+			framework.Logf("Error getting namespace %v: %v", name, err)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return fmt.Errorf("namespace %v from a previous run still terminating after %v", name, namespaceDeletionTimeout)
+	}
+	return nil
+}
+
+// DeleteNamespaces deletes the namespaces created by Cluster Loader with the project parallelism,
+// each worker waiting for the RateLimit.Delay of the project tuning between namespaces, then waits
+// until they are gone and summarizes how long the deletion of each namespace took. Namespaces which
+// failed to be deleted don't stop the others, their errors are returned together with the summary.
+func DeleteNamespaces(c clientset.Interface) (*NamespaceDeletionSummary, error) {
+	parallelLock.Lock()
+	namespaces := managedNamespaces
+	managedNamespaces = nil
+	parallelism := deleteParallelism
+	parallelLock.Unlock()
+	if len(namespaces) == 0 {
+		return nil, nil
+	}
+
+	framework.Logf("Deleting %d namespaces with %d workers", len(namespaces), parallelism)
+	nsCh := make(chan managedNamespace, len(namespaces))
+	for _, ns := range namespaces {
+		nsCh <- ns
+	}
+	close(nsCh)
+	errCh := make(chan error, len(namespaces))
+	var lock sync.Mutex
+	deleted := make(map[string]time.Time, len(namespaces))
+	var wg sync.WaitGroup
+	for w := 0; w < parallelism; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ns := range nsCh {
+				start := time.Now()
+				if err := c.Core().Namespaces().Delete(ns.name, nil); err != nil && !errors.IsNotFound(err) {
+					errCh <- fmt.Errorf("error deleting namespace %v: %v", ns.name, err)
+					continue
+				}
+				lock.Lock()
+				deleted[ns.name] = start
+				if len(deleted)%progressStep(len(namespaces)) == 0 {
+					framework.Logf("Deleted %d/%d namespaces", len(deleted), len(namespaces))
+				}
+				lock.Unlock()
+				if ns.tuning != nil {
+					if err := ns.tuning.Delay(); err != nil {
+						errCh <- err
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errCh)
+	var errs []error
+	for err := range errCh {
+		errs = append(errs, err)
+	}
+
+	// Namespaces are terminating until the namespace controller removed all their content
+	var latencies []framework.PodLatencyData
+	step := progressStep(len(namespaces))
+	err := wait.Poll(namespaceDeletionPoll, namespaceDeletionTimeout, func() (bool, error) {
+		list, err := c.Core().Namespaces().List(metav1.ListOptions{})
+		if err != nil {
+			framework.Logf("Error listing namespaces: %v", err)
+			return false, nil
+		}
+		existing := make(map[string]bool, len(list.Items))
+		for _, ns := range list.Items {
+			existing[ns.Name] = true
+		}
+		now := time.Now()
+		gone := len(latencies)
+		for name, start := range deleted {
+			if !existing[name] {
+				latencies = append(latencies, framework.PodLatencyData{Name: name, Latency: now.Sub(start)})
+				delete(deleted, name)
+			}
+		}
+		if len(latencies)/step > gone/step {
+			framework.Logf("%d/%d namespaces are gone", len(latencies), len(namespaces))
+		}
+		return len(deleted) == 0, nil
+	})
+	summary := &NamespaceDeletionSummary{
+		Namespaces: len(namespaces),
+		Remaining:  len(deleted),
+		Latency:    latencyMetric(latencies),
+	}
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%d namespaces not deleted after %v: %v", len(deleted), namespaceDeletionTimeout, err))
+	}
+	return summary, utilerrors.NewAggregate(errs)
+}
+
+// NamespaceDeletionSummary holds the latency from deleting a namespace until it is gone
+type NamespaceDeletionSummary struct {
+	Namespaces int                     `json:"namespaces"`
+	Remaining  int                     `json:"remaining"`
+	Latency    framework.LatencyMetric `json:"latency"`
+}
+
+// SummaryKind returns the name of the summary
+func (s *NamespaceDeletionSummary) SummaryKind() string {
+	return "NamespaceDeletion"
+}
+
+// PrintHumanReadable prints the summary as a table
+func (s *NamespaceDeletionSummary) PrintHumanReadable() string {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 1, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "Deleted namespaces: %v, not deleted: %v\n", s.Namespaces, s.Remaining)
+	fmt.Fprintf(w, "Latency\tPerc50\tPerc90\tPerc99\tPerc100\n")
+	fmt.Fprintf(w, "deletion\t%v\t%v\t%v\t%v\n", s.Latency.Perc50, s.Latency.Perc90, s.Latency.Perc99, s.Latency.Perc100)
+	w.Flush()
+	return buf.String()
+}
+
+// PrintJSON prints the summary as json
+func (s *NamespaceDeletionSummary) PrintJSON() string {
+	return framework.PrettyPrintJSON(s)
+}
+
+// PerfData converts the deletion latency into a perfdash data item
+func (s *NamespaceDeletionSummary) PerfData() *perftype.PerfData {
+	return &perftype.PerfData{
+		Version:   currentPerfDataVersion,
+		DataItems: []perftype.DataItem{latencyToDataItem(s.Latency, map[string]string{"Metric": "namespace_deletion"})},
+	}
+}
+
+// progressStep returns how often progress of total operations is logged, every 10%
+func progressStep(total int) int {
+	if total < 10 {
+		return 1
+	}
+	return total / 10
+}
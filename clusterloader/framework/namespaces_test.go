@@ -0,0 +1,38 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import "testing"
+
+func TestProgressStep(t *testing.T) {
+	for _, test := range []struct {
+		total    int
+		expected int
+	}{
+		{0, 1},
+		{1, 1},
+		{9, 1},
+		{10, 1},
+		{25, 2},
+		{100, 10},
+		{1005, 100},
+	} {
+		if step := progressStep(test.total); step != test.expected {
+			t.Errorf("progressStep(%d) = %d, expected %d", test.total, step, test.expected)
+		}
+	}
+}
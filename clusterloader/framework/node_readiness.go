@@ -0,0 +1,158 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kubernetes/pkg/api/v1"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	"k8s.io/kubernetes/test/e2e/framework"
+	"k8s.io/kubernetes/test/e2e/perftype"
+)
+
+// nodeReadinessMeasurement tracks nodes registering during the test and reports how long they
+// needed from registration until they were Ready
+type nodeReadinessMeasurement struct {
+	lock sync.Mutex
+	// existing are the nodes registered before the measurement started
+	existing map[string]bool
+	joined   map[string]*joinedNode
+	stopCh   chan struct{}
+}
+
+type joinedNode struct {
+	registered time.Time
+	ready      time.Time
+}
+
+// Start lists the existing nodes and watches for new ones
+func (m *nodeReadinessMeasurement) Start(c clientset.Interface) error {
+	nodes, err := c.Core().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	m.existing = make(map[string]bool, len(nodes.Items))
+	for _, node := range nodes.Items {
+		m.existing[node.Name] = true
+	}
+	m.joined = make(map[string]*joinedNode)
+	m.stopCh = startInformer(&cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return c.Core().Nodes().List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return c.Core().Nodes().Watch(options)
+		},
+	}, &v1.Node{}, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			m.update(obj.(*v1.Node))
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			m.update(obj.(*v1.Node))
+		},
+	})
+	return nil
+}
+
+func (m *nodeReadinessMeasurement) update(node *v1.Node) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.existing[node.Name] {
+		return
+	}
+	n, ok := m.joined[node.Name]
+	if !ok {
+		n = &joinedNode{registered: node.CreationTimestamp.Time}
+		m.joined[node.Name] = n
+	}
+	if !n.ready.IsZero() {
+		return
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady && condition.Status == v1.ConditionTrue {
+			n.ready = condition.LastTransitionTime.Time
+		}
+	}
+}
+
+// Stop summarizes the latencies of the nodes which joined, regardless of the namespaces
+func (m *nodeReadinessMeasurement) Stop(namespaces []*v1.Namespace) (framework.TestDataSummary, error) {
+	close(m.stopCh)
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	summary := &NodeReadinessSummary{JoinedNodes: len(m.joined)}
+	var latencies []framework.PodLatencyData
+	for name, n := range m.joined {
+		if n.ready.IsZero() {
+			summary.NotReadyNodes = append(summary.NotReadyNodes, name)
+			continue
+		}
+		latencies = append(latencies, framework.PodLatencyData{Name: name, Node: name, Latency: n.ready.Sub(n.registered)})
+	}
+	sort.Strings(summary.NotReadyNodes)
+	summary.RegisterToReady = latencyMetric(latencies)
+	return summary, nil
+}
+
+// NodeReadinessSummary holds the register to Ready latency of the nodes which joined during the test
+type NodeReadinessSummary struct {
+	JoinedNodes     int                     `json:"joinedNodes"`
+	NotReadyNodes   []string                `json:"notReadyNodes"`
+	RegisterToReady framework.LatencyMetric `json:"registerToReady"`
+}
+
+// SummaryKind returns the name of the summary
+func (s *NodeReadinessSummary) SummaryKind() string {
+	return "NodeReadiness"
+}
+
+// PrintHumanReadable prints the summary as a table
+func (s *NodeReadinessSummary) PrintHumanReadable() string {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 1, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "Joined nodes: %v, not ready: %v\n", s.JoinedNodes, len(s.NotReadyNodes))
+	fmt.Fprintf(w, "Latency\tPerc50\tPerc90\tPerc99\tPerc100\n")
+	fmt.Fprintf(w, "register_to_ready\t%v\t%v\t%v\t%v\n", s.RegisterToReady.Perc50, s.RegisterToReady.Perc90, s.RegisterToReady.Perc99, s.RegisterToReady.Perc100)
+	for _, name := range s.NotReadyNodes {
+		fmt.Fprintf(w, "Not ready: %v\n", name)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// PrintJSON prints the summary as json
+func (s *NodeReadinessSummary) PrintJSON() string {
+	return framework.PrettyPrintJSON(s)
+}
+
+// PerfData converts the register to Ready latency into a perfdash data item
+func (s *NodeReadinessSummary) PerfData() *perftype.PerfData {
+	return &perftype.PerfData{
+		Version:   currentPerfDataVersion,
+		DataItems: []perftype.DataItem{latencyToDataItem(s.RegisterToReady, map[string]string{"Metric": "register_to_ready"})},
+	}
+}
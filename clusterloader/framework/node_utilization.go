@@ -0,0 +1,223 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/api/v1"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	"k8s.io/kubernetes/test/e2e/framework"
+	"k8s.io/kubernetes/test/e2e/perftype"
+)
+
+const defaultUtilizationInterval = 30 * time.Second
+
+// nodeUtilizationMeasurement periodically samples requested vs allocatable CPU and memory of every node
+type nodeUtilizationMeasurement struct {
+	interval time.Duration
+	lock     sync.Mutex
+	samples  []UtilizationSample
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+func newNodeUtilizationMeasurement(config MeasurementConfig) (*nodeUtilizationMeasurement, error) {
+	m := &nodeUtilizationMeasurement{interval: defaultUtilizationInterval}
+	if interval, ok := config.Params["interval"]; ok {
+		duration, err := time.ParseDuration(interval)
+		if err != nil {
+			return nil, err
+		}
+		if duration <= 0 {
+			return nil, fmt.Errorf("interval must be positive, got %v", duration)
+		}
+		m.interval = duration
+	}
+	return m, nil
+}
+
+// Start samples the nodes every interval until Stop is called
+func (m *nodeUtilizationMeasurement) Start(c clientset.Interface) error {
+	m.stopCh = make(chan struct{})
+	m.doneCh = make(chan struct{})
+	go func() {
+		defer close(m.doneCh)
+		for {
+			if sample, err := sampleUtilization(c); err != nil {
+				framework.Logf("Failed to sample node utilization: %v", err)
+			} else {
+				m.lock.Lock()
+				m.samples = append(m.samples, sample)
+				m.lock.Unlock()
+			}
+			select {
+			case <-m.stopCh:
+				return
+			case <-time.After(m.interval):
+			}
+		}
+	}()
+	return nil
+}
+
+// sampleUtilization computes requested to allocatable ratios of all schedulable nodes
+func sampleUtilization(c clientset.Interface) (UtilizationSample, error) {
+	sample := UtilizationSample{Time: time.Now()}
+	nodes, err := c.Core().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return sample, err
+	}
+	pods, err := c.Core().Pods(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return sample, err
+	}
+	cpuRequested := make(map[string]int64)
+	memoryRequested := make(map[string]int64)
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == "" || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			cpuRequested[pod.Spec.NodeName] += container.Resources.Requests.Cpu().MilliValue()
+			memoryRequested[pod.Spec.NodeName] += container.Resources.Requests.Memory().Value()
+		}
+	}
+
+	var cpuRatios, memoryRatios []float64
+	var cpuAllocatable, memoryAllocatable, cpuTotal, memoryTotal int64
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		cpu := node.Status.Allocatable.Cpu().MilliValue()
+		memory := node.Status.Allocatable.Memory().Value()
+		if cpu == 0 || memory == 0 {
+			continue
+		}
+		cpuAllocatable += cpu
+		memoryAllocatable += memory
+		cpuTotal += cpuRequested[node.Name]
+		memoryTotal += memoryRequested[node.Name]
+		cpuRatios = append(cpuRatios, float64(cpuRequested[node.Name])/float64(cpu))
+		memoryRatios = append(memoryRatios, float64(memoryRequested[node.Name])/float64(memory))
+	}
+	sample.Nodes = len(cpuRatios)
+	if sample.Nodes == 0 {
+		return sample, nil
+	}
+	sample.CPU = newUtilization(float64(cpuTotal)/float64(cpuAllocatable), cpuRatios)
+	sample.Memory = newUtilization(float64(memoryTotal)/float64(memoryAllocatable), memoryRatios)
+	return sample, nil
+}
+
+func newUtilization(cluster float64, ratios []float64) Utilization {
+	u := Utilization{Cluster: cluster, MinNode: math.MaxFloat64}
+	for _, ratio := range ratios {
+		u.MinNode = math.Min(u.MinNode, ratio)
+		u.MaxNode = math.Max(u.MaxNode, ratio)
+	}
+	return u
+}
+
+// Stop ends sampling and returns all samples, the namespaces are ignored as the
+// utilization is a property of the whole cluster
+func (m *nodeUtilizationMeasurement) Stop(_ []*v1.Namespace) (framework.TestDataSummary, error) {
+	close(m.stopCh)
+	<-m.doneCh
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	summary := &NodeUtilizationSummary{Samples: m.samples}
+	for _, sample := range m.samples {
+		summary.PeakCPU = math.Max(summary.PeakCPU, sample.CPU.Cluster)
+		summary.PeakMemory = math.Max(summary.PeakMemory, sample.Memory.Cluster)
+	}
+	return summary, nil
+}
+
+// NodeUtilizationSummary holds the utilization samples gathered during the run
+type NodeUtilizationSummary struct {
+	PeakCPU    float64             `json:"peakCPU"`
+	PeakMemory float64             `json:"peakMemory"`
+	Samples    []UtilizationSample `json:"samples"`
+}
+
+// UtilizationSample is the utilization of the cluster at a point in time
+type UtilizationSample struct {
+	Time   time.Time   `json:"time"`
+	Nodes  int         `json:"nodes"`
+	CPU    Utilization `json:"cpu"`
+	Memory Utilization `json:"memory"`
+}
+
+// Utilization is the fraction of allocatable resources requested by pods, Cluster is
+// computed over the sum of all nodes, MinNode and MaxNode show the spread between nodes
+type Utilization struct {
+	Cluster float64 `json:"cluster"`
+	MinNode float64 `json:"minNode"`
+	MaxNode float64 `json:"maxNode"`
+}
+
+// SummaryKind returns the name of the summary
+func (s *NodeUtilizationSummary) SummaryKind() string {
+	return "NodeUtilization"
+}
+
+// PrintHumanReadable prints the summary as a table
+func (s *NodeUtilizationSummary) PrintHumanReadable() string {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 1, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "Peak cluster utilization: cpu %.3f, memory %.3f\n", s.PeakCPU, s.PeakMemory)
+	fmt.Fprintf(w, "Time\tNodes\tCPU\tCPUMin\tCPUMax\tMemory\tMemoryMin\tMemoryMax\n")
+	for _, sample := range s.Samples {
+		fmt.Fprintf(w, "%v\t%v\t%.3f\t%.3f\t%.3f\t%.3f\t%.3f\t%.3f\n", sample.Time.Format(time.RFC3339), sample.Nodes,
+			sample.CPU.Cluster, sample.CPU.MinNode, sample.CPU.MaxNode,
+			sample.Memory.Cluster, sample.Memory.MinNode, sample.Memory.MaxNode)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// PrintJSON prints the summary as json
+func (s *NodeUtilizationSummary) PrintJSON() string {
+	return framework.PrettyPrintJSON(s)
+}
+
+// PerfData converts the peak utilization into perfdash data items in percent
+func (s *NodeUtilizationSummary) PerfData() *perftype.PerfData {
+	return &perftype.PerfData{
+		Version: currentPerfDataVersion,
+		DataItems: []perftype.DataItem{
+			{
+				Data:   map[string]float64{"Peak": s.PeakCPU * 100},
+				Unit:   "%",
+				Labels: map[string]string{"Resource": "cpu"},
+			},
+			{
+				Data:   map[string]float64{"Peak": s.PeakMemory * 100},
+				Unit:   "%",
+				Labels: map[string]string{"Resource": "memory"},
+			},
+		},
+	}
+}
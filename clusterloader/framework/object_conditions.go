@@ -0,0 +1,263 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kubernetes/pkg/api/v1"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+const (
+	defaultObjectConditionTimeout = 10 * time.Minute
+	objectConditionPollInterval   = 5 * time.Second
+)
+
+// objectConditionsMeasurement watches objects of any resource, including custom resources, and
+// waits until enough of them in the Cluster Loader namespaces report the expected condition
+type objectConditionsMeasurement struct {
+	resource        schema.GroupVersionResource
+	conditionType   string
+	conditionStatus string
+	// count is the number of objects which must report the condition, 0 means all of them
+	count           int
+	timeout         time.Duration
+	namespacePrefix string
+	lock            sync.Mutex
+	// statuses holds the status of the condition for every object by namespace/name
+	statuses map[string]string
+	stopCh   chan struct{}
+}
+
+func newObjectConditionsMeasurement(config MeasurementConfig) (*objectConditionsMeasurement, error) {
+	m := &objectConditionsMeasurement{
+		resource: schema.GroupVersionResource{
+			Group:    config.Params["group"],
+			Version:  config.Params["version"],
+			Resource: config.Params["resource"],
+		},
+		conditionType:   config.Params["conditiontype"],
+		conditionStatus: "True",
+		timeout:         defaultObjectConditionTimeout,
+		namespacePrefix: config.Params["namespaceprefix"],
+	}
+	if m.resource.Version == "" || m.resource.Resource == "" || m.conditionType == "" {
+		return nil, fmt.Errorf("version, resource and conditiontype params are required")
+	}
+	if status, ok := config.Params["conditionstatus"]; ok {
+		m.conditionStatus = status
+	}
+	if count, ok := config.Params["count"]; ok {
+		value, err := strconv.Atoi(count)
+		if err != nil {
+			return nil, err
+		}
+		m.count = value
+	}
+	if timeout, ok := config.Params["timeout"]; ok {
+		duration, err := time.ParseDuration(timeout)
+		if err != nil {
+			return nil, err
+		}
+		if duration <= 0 {
+			return nil, fmt.Errorf("timeout must be positive, got %v", duration)
+		}
+		m.timeout = duration
+	}
+	return m, nil
+}
+
+// Start watches the objects of the resource in all namespaces
+func (m *objectConditionsMeasurement) Start(_ clientset.Interface) error {
+	config, err := framework.LoadConfig()
+	if err != nil {
+		return err
+	}
+	config.GroupVersion = &schema.GroupVersion{Group: m.resource.Group, Version: m.resource.Version}
+	config.APIPath = "/apis"
+	if m.resource.Group == "" {
+		config.APIPath = "/api"
+	}
+	client, err := dynamic.NewClient(config)
+	if err != nil {
+		return err
+	}
+	resourceClient := client.Resource(&metav1.APIResource{Name: m.resource.Resource, Namespaced: true}, metav1.NamespaceAll)
+	m.statuses = make(map[string]string)
+	m.stopCh = startInformer(&cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return resourceClient.List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return resourceClient.Watch(options)
+		},
+	}, &unstructured.Unstructured{}, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			m.update(obj.(*unstructured.Unstructured))
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			m.update(obj.(*unstructured.Unstructured))
+		},
+		DeleteFunc: func(obj interface{}) {
+			if u, ok := obj.(*unstructured.Unstructured); ok {
+				m.lock.Lock()
+				delete(m.statuses, u.GetNamespace()+"/"+u.GetName())
+				m.lock.Unlock()
+			}
+		},
+	})
+	return nil
+}
+
+func (m *objectConditionsMeasurement) update(u *unstructured.Unstructured) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.statuses[u.GetNamespace()+"/"+u.GetName()] = conditionStatus(u, m.conditionType)
+}
+
+// conditionStatus returns the status of the condition from status.conditions, or "" if the object doesn't report it
+func conditionStatus(u *unstructured.Unstructured, conditionType string) string {
+	status, ok := u.Object["status"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	conditions, ok := status["conditions"].([]interface{})
+	if !ok {
+		return ""
+	}
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != conditionType {
+			continue
+		}
+		if s, ok := condition["status"].(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+// Stop waits until enough objects in the namespaces report the condition and lists which did and which didn't
+func (m *objectConditionsMeasurement) Stop(namespaces []*v1.Namespace) (framework.TestDataSummary, error) {
+	defer close(m.stopCh)
+	names := namespaceSet(namespaces)
+	var summary *ObjectConditionsSummary
+	err := wait.PollImmediate(objectConditionPollInterval, m.timeout, func() (bool, error) {
+		summary = m.summarize(names)
+		if m.count == 0 {
+			return len(summary.Failures) == 0 && len(summary.Successes) > 0, nil
+		}
+		return len(summary.Successes) >= m.count, nil
+	})
+	if err != nil {
+		PrintSummary(summary)
+		return nil, fmt.Errorf("only %d %v report %v=%v after %v", len(summary.Successes), m.resource.Resource, m.conditionType, m.conditionStatus, m.timeout)
+	}
+	return summary, nil
+}
+
+func (m *objectConditionsMeasurement) summarize(namespaces map[string]bool) *ObjectConditionsSummary {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	summary := &ObjectConditionsSummary{
+		Resource:  m.resource.String(),
+		Condition: m.conditionType + "=" + m.conditionStatus,
+		Expected:  m.count,
+	}
+	for key, status := range m.statuses {
+		namespace := key[:strings.Index(key, "/")]
+		if !namespaces[namespace] || !strings.HasPrefix(namespace, m.namespacePrefix) {
+			continue
+		}
+		if status == m.conditionStatus {
+			summary.Successes = append(summary.Successes, key)
+		} else {
+			summary.Failures = append(summary.Failures, ObjectCondition{Name: key, Status: status})
+		}
+	}
+	sort.Strings(summary.Successes)
+	sort.Sort(byObjectName(summary.Failures))
+	return summary
+}
+
+// ObjectConditionsSummary lists the objects which reported the expected condition and those which didn't
+type ObjectConditionsSummary struct {
+	Resource  string            `json:"resource"`
+	Condition string            `json:"condition"`
+	Expected  int               `json:"expected"`
+	Successes []string          `json:"successes"`
+	Failures  []ObjectCondition `json:"failures"`
+}
+
+// ObjectCondition is the status of the condition of an object, empty if the object doesn't report the condition
+type ObjectCondition struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+type byObjectName []ObjectCondition
+
+func (o byObjectName) Len() int           { return len(o) }
+func (o byObjectName) Swap(i, j int)      { o[i], o[j] = o[j], o[i] }
+func (o byObjectName) Less(i, j int) bool { return o[i].Name < o[j].Name }
+
+// SummaryKind returns the name of the summary
+func (s *ObjectConditionsSummary) SummaryKind() string {
+	return "ObjectConditions"
+}
+
+// PrintHumanReadable prints the summary as a table
+func (s *ObjectConditionsSummary) PrintHumanReadable() string {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 1, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "%v with %v: %d, without: %d, expected: %d\n", s.Resource, s.Condition, len(s.Successes), len(s.Failures), s.Expected)
+	fmt.Fprintf(w, "Object\tStatus\n")
+	for _, name := range s.Successes {
+		fmt.Fprintf(w, "%v\t%v\n", name, s.Condition[strings.Index(s.Condition, "=")+1:])
+	}
+	for _, failure := range s.Failures {
+		status := failure.Status
+		if status == "" {
+			status = "<none>"
+		}
+		fmt.Fprintf(w, "%v\t%v\n", failure.Name, status)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// PrintJSON prints the summary as json
+func (s *ObjectConditionsSummary) PrintJSON() string {
+	return framework.PrettyPrintJSON(s)
+}
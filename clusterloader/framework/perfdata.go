@@ -0,0 +1,46 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"k8s.io/kubernetes/test/e2e/framework"
+	"k8s.io/kubernetes/test/e2e/perftype"
+)
+
+// currentPerfDataVersion is the version of the perf data emitted by the measurements. It should
+// be bumped each time an incompatible change is made to the data items.
+const currentPerfDataVersion = "v1"
+
+// PerfDataSummary is a summary which can also be converted into the perfdash data format
+type PerfDataSummary interface {
+	framework.TestDataSummary
+	PerfData() *perftype.PerfData
+}
+
+// latencyToDataItem converts a latency metric into a data item in milliseconds
+func latencyToDataItem(latency framework.LatencyMetric, labels map[string]string) perftype.DataItem {
+	return perftype.DataItem{
+		Data: map[string]float64{
+			"Perc50":  float64(latency.Perc50) / 1000000, // ns -> ms
+			"Perc90":  float64(latency.Perc90) / 1000000,
+			"Perc99":  float64(latency.Perc99) / 1000000,
+			"Perc100": float64(latency.Perc100) / 1000000,
+		},
+		Unit:   "ms",
+		Labels: labels,
+	}
+}
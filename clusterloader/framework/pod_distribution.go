@@ -0,0 +1,175 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"text/tabwriter"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/api/v1"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+// noZone is used in the summary for nodes without the zone label
+const noZone = "<none>"
+
+// podDistributionMeasurement reports how the pods created by Cluster Loader are spread across nodes and zones
+type podDistributionMeasurement struct {
+	c clientset.Interface
+}
+
+// Start only keeps the client, the distribution is computed once all pods are running
+func (m *podDistributionMeasurement) Start(c clientset.Interface) error {
+	m.c = c
+	return nil
+}
+
+// Stop computes the distribution of scheduled pods from the namespaces
+func (m *podDistributionMeasurement) Stop(namespaces []*v1.Namespace) (framework.TestDataSummary, error) {
+	nodes, err := m.c.Core().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	perNode := make(map[string]int)
+	nodeZone := make(map[string]string)
+	perZone := make(map[string]int)
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		zone, ok := node.Labels[metav1.LabelZoneFailureDomain]
+		if !ok {
+			zone = noZone
+		}
+		perNode[node.Name] = 0
+		nodeZone[node.Name] = zone
+		perZone[zone] = 0
+	}
+	for _, ns := range namespaces {
+		pods, err := m.c.Core().Pods(ns.Name).List(metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, pod := range pods.Items {
+			if pod.Spec.NodeName == "" {
+				continue
+			}
+			perNode[pod.Spec.NodeName]++
+			if zone, ok := nodeZone[pod.Spec.NodeName]; ok {
+				perZone[zone]++
+			}
+		}
+	}
+
+	summary := &PodDistributionSummary{
+		Nodes: newDistribution(perNode),
+		Zones: newDistribution(perZone),
+	}
+	for zone, count := range perZone {
+		summary.PodsPerZone = append(summary.PodsPerZone, ZonePods{Zone: zone, Pods: count})
+	}
+	sort.Sort(byZone(summary.PodsPerZone))
+	return summary, nil
+}
+
+// newDistribution computes the statistics of the pod counts per domain
+func newDistribution(counts map[string]int) Distribution {
+	d := Distribution{Domains: len(counts)}
+	if len(counts) == 0 {
+		return d
+	}
+	d.Min = math.MaxInt32
+	for _, count := range counts {
+		d.Pods += count
+		if count > d.Max {
+			d.Max = count
+		}
+		if count < d.Min {
+			d.Min = count
+		}
+	}
+	d.Mean = float64(d.Pods) / float64(d.Domains)
+	if d.Mean == 0 {
+		return d
+	}
+	variance := 0.0
+	for _, count := range counts {
+		variance += (float64(count) - d.Mean) * (float64(count) - d.Mean)
+	}
+	d.ImbalanceIndex = math.Sqrt(variance/float64(d.Domains)) / d.Mean
+	return d
+}
+
+// PodDistributionSummary holds the spreading of pods across nodes and zones
+type PodDistributionSummary struct {
+	Nodes       Distribution `json:"nodes"`
+	Zones       Distribution `json:"zones"`
+	PodsPerZone []ZonePods   `json:"podsPerZone"`
+}
+
+// Distribution describes pod counts per domain (node or zone), ImbalanceIndex is the
+// coefficient of variation of the counts, 0 means a perfectly even spread
+type Distribution struct {
+	Domains        int     `json:"domains"`
+	Pods           int     `json:"pods"`
+	Min            int     `json:"min"`
+	Max            int     `json:"max"`
+	Mean           float64 `json:"mean"`
+	ImbalanceIndex float64 `json:"imbalanceIndex"`
+}
+
+// ZonePods is the number of pods scheduled in a zone
+type ZonePods struct {
+	Zone string `json:"zone"`
+	Pods int    `json:"pods"`
+}
+
+type byZone []ZonePods
+
+func (z byZone) Len() int           { return len(z) }
+func (z byZone) Swap(i, j int)      { z[i], z[j] = z[j], z[i] }
+func (z byZone) Less(i, j int) bool { return z[i].Zone < z[j].Zone }
+
+// SummaryKind returns the name of the summary
+func (s *PodDistributionSummary) SummaryKind() string {
+	return "PodDistribution"
+}
+
+// PrintHumanReadable prints the summary as a table
+func (s *PodDistributionSummary) PrintHumanReadable() string {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 1, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "Domain\tCount\tPods\tMin\tMax\tMean\tImbalance\n")
+	fmt.Fprintf(w, "node\t%v\t%v\t%v\t%v\t%.2f\t%.3f\n", s.Nodes.Domains, s.Nodes.Pods, s.Nodes.Min, s.Nodes.Max, s.Nodes.Mean, s.Nodes.ImbalanceIndex)
+	fmt.Fprintf(w, "zone\t%v\t%v\t%v\t%v\t%.2f\t%.3f\n", s.Zones.Domains, s.Zones.Pods, s.Zones.Min, s.Zones.Max, s.Zones.Mean, s.Zones.ImbalanceIndex)
+	fmt.Fprintf(w, "\nZone\tPods\n")
+	for _, zone := range s.PodsPerZone {
+		fmt.Fprintf(w, "%v\t%v\n", zone.Zone, zone.Pods)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// PrintJSON prints the summary as json
+func (s *PodDistributionSummary) PrintJSON() string {
+	return framework.PrettyPrintJSON(s)
+}
@@ -33,9 +33,15 @@ func CreatePods(f *framework.Framework, name, namespace string, labels labels.Se
 	for i := 0; i < maxCount; i++ {
 		framework.Logf("%v/%v : Creating pod", i+1, maxCount)
 		podObj := newPod(name, namespace, i, labels, spec)
+		if tuning != nil && tuning.Pods.Quota.Wait {
+			if err := tuning.Pods.waitForQuota(objectClient(f), namespace, spec); err != nil {
+				return fmt.Errorf("waiting for quota: %v", err)
+			}
+		}
 		if _, err := createNewPodWithRetries(f, namespace, podObj); err != nil {
 			return err
 		}
+		ObjectsCreated(1)
 		if tuning == nil {
 			continue
 		}
@@ -77,14 +83,13 @@ func CreatePods(f *framework.Framework, name, namespace string, labels labels.Se
 	return nil
 }
 
-// createNewPodWithRetries uses polling to retry pod creation
+// createNewPodWithRetries retries pod creation with backoff on transient errors
 func createNewPodWithRetries(f *framework.Framework, namespace string, podObj *v1.Pod) (pod *v1.Pod, err error) {
-	for retryCount := 0; retryCount < maxRetries; retryCount++ {
-		pod, err = f.ClientSet.Core().Pods(namespace).Create(podObj)
-		if err == nil {
-			break
-		}
-	}
+	err = retryWithBackoff(fmt.Sprintf("creating pod %v/%v", namespace, podObj.Name), func() error {
+		recordPodWrite(namespace, podObj.Name)
+		pod, err = objectClient(f).Core().Pods(namespace).Create(podObj)
+		return err
+	})
 	return
 }
 
@@ -0,0 +1,130 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+// defaultHost is the host the e2e framework picks when neither a kubeconfig nor a host is set
+const defaultHost = "http://127.0.0.1:8080"
+
+// DefaultKubeconfig falls back to ~/.kube/config, the same as kubectl, when neither --kubeconfig,
+// KUBECONFIG nor --host is set. Without one inside a pod the default host is cleared instead, so
+// kubectl isn't pointed at localhost and uses the in-cluster config like the clients of the test.
+// It must be called after the flags are parsed.
+func DefaultKubeconfig() {
+	if framework.TestContext.KubeConfig != "" || framework.TestContext.Host != defaultHost {
+		return
+	}
+	if _, err := os.Stat(clientcmd.RecommendedHomeFile); err == nil {
+		framework.TestContext.KubeConfig = clientcmd.RecommendedHomeFile
+		framework.TestContext.Host = ""
+		return
+	}
+	if _, err := restclient.InClusterConfig(); err == nil {
+		framework.TestContext.Host = ""
+	}
+}
+
+// CheckCredentials fails fast with a clear message when the cluster can't be reached or rejects
+// the credentials, instead of the framework retrying to create its namespace
+func CheckCredentials() error {
+	context := framework.TestContext.KubeContext
+	if context == "" {
+		context = "<current>"
+	}
+	config, err := framework.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("error loading kubeconfig %q with context %v: %v", framework.TestContext.KubeConfig, context, err)
+	}
+	c, err := clientset.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	_, err = c.Core().Namespaces().Get(metav1.NamespaceDefault, metav1.GetOptions{})
+	switch {
+	case err == nil || errors.IsForbidden(err) || errors.IsNotFound(err):
+		// The credentials were accepted, missing permissions show up where they are needed
+		return nil
+	case errors.IsUnauthorized(err):
+		return fmt.Errorf("%v rejected the credentials of kubeconfig %q context %v: %v", config.Host, framework.TestContext.KubeConfig, context, err)
+	default:
+		return fmt.Errorf("error reaching %v with kubeconfig %q context %v: %v", config.Host, framework.TestContext.KubeConfig, context, err)
+	}
+}
+
+// Preflight prints the cluster the test is about to load and refuses to continue against clusters
+// larger than the configured threshold unless the run was confirmed by flag or on the terminal.
+// It uses a client of its own so it can run before the framework writes anything to the cluster.
+func Preflight(preflight PreflightConfig) error {
+	config, err := framework.LoadConfig()
+	if err != nil {
+		return err
+	}
+	c, err := clientset.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	version, err := c.Discovery().ServerVersion()
+	if err != nil {
+		return fmt.Errorf("error getting server version: %v", err)
+	}
+	nodes, err := c.Core().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error listing nodes: %v", err)
+	}
+	context := framework.TestContext.KubeContext
+	if context == "" {
+		context = "<current>"
+	}
+	framework.Logf("Target cluster: context %v, endpoint %v, %d nodes, version %v", context, config.Host, len(nodes.Items), version.GitVersion)
+
+	if preflight.MaxNodes == 0 || len(nodes.Items) <= preflight.MaxNodes || confirmLargeCluster {
+		return nil
+	}
+	if !isTerminal(os.Stdin) {
+		return fmt.Errorf("cluster %v has %d nodes, more than preflight.maxnodes %d; pass --confirm-large-cluster to run anyway", config.Host, len(nodes.Items), preflight.MaxNodes)
+	}
+	fmt.Printf("Cluster %v has %d nodes, more than preflight.maxnodes %d.\nType the endpoint to continue: ", config.Host, len(nodes.Items), preflight.MaxNodes)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("error reading confirmation: %v", err)
+	}
+	if strings.TrimSpace(answer) != config.Host {
+		return fmt.Errorf("run against %v was not confirmed", config.Host)
+	}
+	return nil
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+var (
+	progressLock sync.Mutex
+	progress     Progress
+	// statusMux serves the status and metrics endpoints on --status-address
+	statusMux = http.NewServeMux()
+)
+
+// Progress is how far the creation of the projects got
+type Progress struct {
+	Project string    `json:"project"`
+	Created int       `json:"created"`
+	Total   int       `json:"total"`
+	Errors  int       `json:"errors"`
+	Start   time.Time `json:"start"`
+	// Rate is the average number of objects created per second since the start
+	Rate float64       `json:"rate"`
+	ETA  time.Duration `json:"eta"`
+}
+
+// ExpectedObjects returns the number of pods, RCs and template copies the projects create,
+// with the mean of sampled counts
+func ExpectedObjects(projects []ClusterLoader) int {
+	total := 0.0
+	for _, p := range projects {
+		namespaces := p.Number
+		if len(p.Namespaces) > 0 {
+			namespaces = len(p.Namespaces)
+		}
+		for _, objects := range [][]ClusterLoaderObject{p.Pods, p.RCs, p.Templates} {
+			for _, object := range objects {
+				total += float64(namespaces) * object.meanCount()
+			}
+		}
+	}
+	return int(total)
+}
+
+// StartProgress logs the progress of the run every --progress-interval, unless it isn't positive, and
+// serves it on --status-address, the returned function stops the reporting
+func StartProgress(total int) func() {
+	progressLock.Lock()
+	progress = Progress{Total: total, Start: time.Now()}
+	progressLock.Unlock()
+
+	if statusAddress != "" {
+		statusMux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(CurrentProgress())
+		})
+		go func() {
+			framework.Logf("Serving status on %v", statusAddress)
+			if err := http.ListenAndServe(statusAddress, statusMux); err != nil {
+				framework.Logf("Error serving status: %v", err)
+			}
+		}()
+	}
+
+	stopCh := make(chan struct{})
+	if progressInterval <= 0 {
+		// A non-positive interval disables the periodic logging, the status is still served
+		return func() { close(stopCh) }
+	}
+	go func() {
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C:
+				p := CurrentProgress()
+				framework.Logf("Progress: %d/%d objects created in project %v, %.1f objects/s, %d errors, ETA %v",
+					p.Created, p.Total, p.Project, p.Rate, p.Errors, p.ETA)
+			}
+		}
+	}()
+	return func() { close(stopCh) }
+}
+
+// SetProgressProject records the project whose objects are being created
+func SetProgressProject(project string) {
+	progressLock.Lock()
+	defer progressLock.Unlock()
+	progress.Project = project
+}
+
+// ObjectsCreated counts objects of the projects which were created
+func ObjectsCreated(n int) {
+	progressLock.Lock()
+	defer progressLock.Unlock()
+	progress.Created += n
+}
+
+// progressError counts object operations which failed after all retries
+func progressError() {
+	progressLock.Lock()
+	defer progressLock.Unlock()
+	progress.Errors++
+}
+
+// CurrentProgress returns the progress with the rate and ETA as of now
+func CurrentProgress() Progress {
+	progressLock.Lock()
+	defer progressLock.Unlock()
+	p := progress
+	if p.Start.IsZero() {
+		return p
+	}
+	elapsed := time.Since(p.Start)
+	if p.Created > 0 && elapsed > 0 {
+		p.Rate = float64(p.Created) / elapsed.Seconds()
+		if remaining := p.Total - p.Created; remaining > 0 {
+			p.ETA = time.Duration(float64(remaining)/p.Rate) * time.Second
+		}
+	}
+	return p
+}
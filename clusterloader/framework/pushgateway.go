@@ -0,0 +1,132 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	"k8s.io/kubernetes/test/e2e/framework"
+	"k8s.io/kubernetes/test/e2e/perftype"
+)
+
+const defaultPushgatewayJob = "clusterloader"
+
+// reservedPushgatewayLabels are set by Cluster Loader and can't be configured in pushgateway.labels
+var reservedPushgatewayLabels = []string{"bucket", "nodes"}
+
+// unitSuffixes maps perf data units to prometheus metric name suffixes
+var unitSuffixes = map[string]string{
+	"ms":  "milliseconds",
+	"%":   "percent",
+	"1/s": "per_second",
+	"MiB": "mebibytes",
+}
+
+// PushSummaries pushes the perf data of the summaries to the Pushgateway as gauges named
+// clusterloader_<kind>_<unit>, labeled with the configured labels and the cluster size
+func PushSummaries(c clientset.Interface, config PushgatewayConfig, summaries []framework.TestDataSummary) error {
+	if config.URL == "" {
+		return nil
+	}
+	job := config.Job
+	if job == "" {
+		job = defaultPushgatewayJob
+	}
+	constLabels := prometheus.Labels{}
+	nodes, err := c.Core().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	constLabels["nodes"] = strconv.Itoa(len(nodes.Items))
+	for name, value := range config.Labels {
+		constLabels[name] = value
+	}
+
+	var collectors []prometheus.Collector
+	pushed := 0
+	for _, summary := range summaries {
+		perfSummary, ok := summary.(PerfDataSummary)
+		if !ok {
+			continue
+		}
+		perfData := perfSummary.PerfData()
+		if len(perfData.DataItems) == 0 {
+			continue
+		}
+		// Items with different units go into separate gauges, the items of a unit share the label names
+		var units []string
+		items := make(map[string][]perftype.DataItem)
+		for _, item := range perfData.DataItems {
+			if _, ok := items[item.Unit]; !ok {
+				units = append(units, item.Unit)
+			}
+			items[item.Unit] = append(items[item.Unit], item)
+		}
+		for _, unit := range units {
+			collectors = append(collectors, newSummaryGauge(summary.SummaryKind(), unit, items[unit], constLabels))
+		}
+		pushed++
+	}
+	if len(collectors) == 0 {
+		return nil
+	}
+	if err := prometheus.PushCollectors(job, "", config.URL, collectors...); err != nil {
+		return err
+	}
+	framework.Logf("Pushed %d summaries to %v", pushed, config.URL)
+	return nil
+}
+
+// newSummaryGauge returns the gauge named clusterloader_<kind>_<unit> holding the data items of the unit.
+// Its label names are those of all items, items without one of them get it with an empty value.
+func newSummaryGauge(kind, unit string, items []perftype.DataItem, constLabels prometheus.Labels) *prometheus.GaugeVec {
+	names := make(map[string]bool)
+	for _, item := range items {
+		for name := range item.Labels {
+			names[name] = true
+		}
+	}
+	labelNames := []string{"bucket"}
+	for name := range names {
+		labelNames = append(labelNames, name)
+	}
+	sort.Strings(labelNames[1:])
+	name := "clusterloader_" + strings.ToLower(kind)
+	if suffix, ok := unitSuffixes[unit]; ok {
+		name += "_" + suffix
+	}
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:        name,
+		Help:        kind + " measured by Cluster Loader in " + unit,
+		ConstLabels: constLabels,
+	}, labelNames)
+	for _, item := range items {
+		for bucket, value := range item.Data {
+			labels := prometheus.Labels{"bucket": bucket}
+			for name := range names {
+				labels[name] = item.Labels[name]
+			}
+			gauge.With(labels).Set(value)
+		}
+	}
+	return gauge
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/kubernetes/test/e2e/perftype"
+)
+
+// gaugeValues collects the values of the gauge by their labels, formatted as name=value pairs
+func gaugeValues(t *testing.T, gauge *prometheus.GaugeVec) map[string]float64 {
+	ch := make(chan prometheus.Metric, 100)
+	gauge.Collect(ch)
+	close(ch)
+	values := make(map[string]float64)
+	for metric := range ch {
+		out := &dto.Metric{}
+		if err := metric.Write(out); err != nil {
+			t.Fatalf("error writing metric: %v", err)
+		}
+		var labels []string
+		for _, label := range out.Label {
+			labels = append(labels, label.GetName()+"="+label.GetValue())
+		}
+		values[strings.Join(labels, ",")] = out.Gauge.GetValue()
+	}
+	return values
+}
+
+func TestNewSummaryGauge(t *testing.T) {
+	constLabels := prometheus.Labels{"nodes": "100"}
+	for _, test := range []struct {
+		name         string
+		kind         string
+		unit         string
+		items        []perftype.DataItem
+		expectedName string
+		expected     map[string]float64
+	}{
+		{
+			name: "latency",
+			kind: "PVLatency",
+			unit: "ms",
+			items: []perftype.DataItem{
+				{Data: map[string]float64{"Perc50": 10, "Perc99": 20}, Unit: "ms", Labels: map[string]string{"Metric": "bind"}},
+				{Data: map[string]float64{"Perc50": 30}, Unit: "ms", Labels: map[string]string{"Metric": "provision"}},
+			},
+			expectedName: "clusterloader_pvlatency_milliseconds",
+			expected: map[string]float64{
+				"Metric=bind,bucket=Perc50,nodes=100":      10,
+				"Metric=bind,bucket=Perc99,nodes=100":      20,
+				"Metric=provision,bucket=Perc50,nodes=100": 30,
+			},
+		},
+		{
+			name: "different label names",
+			kind: "Events",
+			unit: "1/s",
+			items: []perftype.DataItem{
+				{Data: map[string]float64{"Rate": 1}, Unit: "1/s", Labels: map[string]string{"Reason": "Pulled"}},
+				{Data: map[string]float64{"Rate": 2}, Unit: "1/s", Labels: map[string]string{"Kind": "Pod"}},
+				{Data: map[string]float64{"Rate": 3}, Unit: "1/s"},
+			},
+			expectedName: "clusterloader_events_per_second",
+			expected: map[string]float64{
+				"Kind=,Reason=Pulled,bucket=Rate,nodes=100": 1,
+				"Kind=Pod,Reason=,bucket=Rate,nodes=100":    2,
+				"Kind=,Reason=,bucket=Rate,nodes=100":       3,
+			},
+		},
+		{
+			name:         "unknown unit",
+			kind:         "Objects",
+			unit:         "objects",
+			items:        []perftype.DataItem{{Data: map[string]float64{"Count": 5}, Unit: "objects"}},
+			expectedName: "clusterloader_objects",
+			expected:     map[string]float64{"bucket=Count,nodes=100": 5},
+		},
+		{
+			name:         "no items",
+			kind:         "Empty",
+			unit:         "ms",
+			expectedName: "clusterloader_empty_milliseconds",
+			expected:     map[string]float64{},
+		},
+	} {
+		gauge := newSummaryGauge(test.kind, test.unit, test.items, constLabels)
+		descs := make(chan *prometheus.Desc, 1)
+		gauge.Describe(descs)
+		if desc := (<-descs).String(); !strings.Contains(desc, `fqName: "`+test.expectedName+`"`) {
+			t.Errorf("%v: expected gauge %v, got %v", test.name, test.expectedName, desc)
+		}
+		if values := gaugeValues(t, gauge); !reflect.DeepEqual(values, test.expected) {
+			t.Errorf("%v: expected values %v, got %v", test.name, test.expected, values)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kubernetes/pkg/api/v1"
+	v1helper "k8s.io/kubernetes/pkg/api/v1/helper"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	"k8s.io/kubernetes/test/e2e/framework"
+	"k8s.io/kubernetes/test/e2e/perftype"
+)
+
+// defaultStorageClass is used in the summary for claims without a storage class
+const defaultStorageClass = "<default>"
+
+// pvLatencyMeasurement records PVC create->Bound latency and the time pods using claims
+// need from being scheduled to running, which covers volume attach and mount
+type pvLatencyMeasurement struct {
+	lock      sync.Mutex
+	claims    map[string]*claimTimes
+	pods      map[string]*podVolumeTimes
+	claimStop chan struct{}
+	podStop   chan struct{}
+}
+
+type claimTimes struct {
+	namespace    string
+	name         string
+	storageClass string
+	created      time.Time
+	bound        time.Time
+}
+
+type podVolumeTimes struct {
+	namespace string
+	name      string
+	node      string
+	claims    []string
+	scheduled time.Time
+	running   time.Time
+}
+
+// Start watches claims and pods in all namespaces
+func (m *pvLatencyMeasurement) Start(c clientset.Interface) error {
+	m.claims = make(map[string]*claimTimes)
+	m.pods = make(map[string]*podVolumeTimes)
+	m.claimStop = startInformer(&cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return c.Core().PersistentVolumeClaims(metav1.NamespaceAll).List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return c.Core().PersistentVolumeClaims(metav1.NamespaceAll).Watch(options)
+		},
+	}, &v1.PersistentVolumeClaim{}, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			m.updateClaim(obj.(*v1.PersistentVolumeClaim))
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			m.updateClaim(obj.(*v1.PersistentVolumeClaim))
+		},
+	})
+	m.podStop = startInformer(&cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return c.Core().Pods(metav1.NamespaceAll).List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return c.Core().Pods(metav1.NamespaceAll).Watch(options)
+		},
+	}, &v1.Pod{}, cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			m.updatePod(obj.(*v1.Pod))
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			m.updatePod(obj.(*v1.Pod))
+		},
+	})
+	return nil
+}
+
+func (m *pvLatencyMeasurement) updateClaim(claim *v1.PersistentVolumeClaim) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	key := claim.Namespace + "/" + claim.Name
+	times, ok := m.claims[key]
+	if !ok {
+		storageClass := v1helper.GetPersistentVolumeClaimClass(claim)
+		if storageClass == "" {
+			storageClass = defaultStorageClass
+		}
+		times = &claimTimes{
+			namespace:    claim.Namespace,
+			name:         claim.Name,
+			storageClass: storageClass,
+			created:      claim.CreationTimestamp.Time,
+		}
+		m.claims[key] = times
+	}
+	if times.bound.IsZero() && claim.Status.Phase == v1.ClaimBound {
+		times.bound = time.Now()
+	}
+}
+
+func (m *pvLatencyMeasurement) updatePod(pod *v1.Pod) {
+	var claims []string
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			claims = append(claims, pod.Namespace+"/"+volume.PersistentVolumeClaim.ClaimName)
+		}
+	}
+	if len(claims) == 0 {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	key := pod.Namespace + "/" + pod.Name
+	times, ok := m.pods[key]
+	if !ok {
+		times = &podVolumeTimes{namespace: pod.Namespace, name: pod.Name, claims: claims}
+		m.pods[key] = times
+	}
+	if times.scheduled.IsZero() {
+		if _, condition := podutil.GetPodCondition(&pod.Status, v1.PodScheduled); condition != nil && condition.Status == v1.ConditionTrue {
+			times.scheduled = condition.LastTransitionTime.Time
+			times.node = pod.Spec.NodeName
+		}
+	}
+	if times.running.IsZero() && pod.Status.Phase == v1.PodRunning {
+		times.running = time.Now()
+	}
+}
+
+// Stop summarizes the latencies per storage class for claims and pods in the namespaces
+func (m *pvLatencyMeasurement) Stop(namespaces []*v1.Namespace) (framework.TestDataSummary, error) {
+	close(m.claimStop)
+	close(m.podStop)
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	nsSet := namespaceSet(namespaces)
+	provisioning := make(map[string][]framework.PodLatencyData)
+	mount := make(map[string][]framework.PodLatencyData)
+	unbound := make(map[string]int)
+	for _, claim := range m.claims {
+		if !nsSet[claim.namespace] {
+			continue
+		}
+		if claim.bound.IsZero() {
+			unbound[claim.storageClass]++
+			continue
+		}
+		provisioning[claim.storageClass] = append(provisioning[claim.storageClass], framework.PodLatencyData{
+			Name:    claim.name,
+			Latency: claim.bound.Sub(claim.created),
+		})
+	}
+	for _, pod := range m.pods {
+		if !nsSet[pod.namespace] || pod.scheduled.IsZero() || pod.running.IsZero() {
+			continue
+		}
+		storageClass := defaultStorageClass
+		if claim, ok := m.claims[pod.claims[0]]; ok {
+			storageClass = claim.storageClass
+		}
+		mount[storageClass] = append(mount[storageClass], framework.PodLatencyData{
+			Name:    pod.name,
+			Node:    pod.node,
+			Latency: pod.running.Sub(pod.scheduled),
+		})
+	}
+
+	summary := &PVLatencySummary{StorageClasses: make(map[string]*StorageClassLatency)}
+	get := func(storageClass string) *StorageClassLatency {
+		if _, ok := summary.StorageClasses[storageClass]; !ok {
+			summary.StorageClasses[storageClass] = &StorageClassLatency{}
+		}
+		return summary.StorageClasses[storageClass]
+	}
+	for storageClass, latencies := range provisioning {
+		get(storageClass).BoundClaims = len(latencies)
+		get(storageClass).Provisioning = latencyMetric(latencies)
+	}
+	for storageClass, count := range unbound {
+		get(storageClass).UnboundClaims = count
+	}
+	for storageClass, latencies := range mount {
+		get(storageClass).Pods = len(latencies)
+		get(storageClass).AttachAndMount = latencyMetric(latencies)
+	}
+	return summary, nil
+}
+
+// PVLatencySummary holds volume latencies grouped by storage class
+type PVLatencySummary struct {
+	StorageClasses map[string]*StorageClassLatency `json:"storageClasses"`
+}
+
+// StorageClassLatency holds volume latencies of a single storage class
+type StorageClassLatency struct {
+	BoundClaims    int                     `json:"boundClaims"`
+	UnboundClaims  int                     `json:"unboundClaims"`
+	Provisioning   framework.LatencyMetric `json:"provisioning"`
+	Pods           int                     `json:"pods"`
+	AttachAndMount framework.LatencyMetric `json:"attachAndMount"`
+}
+
+// SummaryKind returns the name of the summary
+func (s *PVLatencySummary) SummaryKind() string {
+	return "PVLatency"
+}
+
+// PrintHumanReadable prints the summary as a table
+func (s *PVLatencySummary) PrintHumanReadable() string {
+	var storageClasses []string
+	for storageClass := range s.StorageClasses {
+		storageClasses = append(storageClasses, storageClass)
+	}
+	sort.Strings(storageClasses)
+
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 1, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "StorageClass\tBound\tUnbound\tProvisioning50\tProvisioning99\tPods\tMount50\tMount99\n")
+	for _, storageClass := range storageClasses {
+		l := s.StorageClasses[storageClass]
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", storageClass, l.BoundClaims, l.UnboundClaims,
+			l.Provisioning.Perc50, l.Provisioning.Perc99, l.Pods, l.AttachAndMount.Perc50, l.AttachAndMount.Perc99)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// PrintJSON prints the summary as json
+func (s *PVLatencySummary) PrintJSON() string {
+	return framework.PrettyPrintJSON(s)
+}
+
+// PerfData converts the summary into perfdash data items, one per storage class and metric
+func (s *PVLatencySummary) PerfData() *perftype.PerfData {
+	// The storage classes are sorted so the data items are in the same order in every run
+	var classes []string
+	for class := range s.StorageClasses {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	perfData := &perftype.PerfData{Version: currentPerfDataVersion}
+	for _, class := range classes {
+		latency := s.StorageClasses[class]
+		perfData.DataItems = append(perfData.DataItems,
+			latencyToDataItem(latency.Provisioning, map[string]string{"Metric": "provisioning", "StorageClass": class}),
+			latencyToDataItem(latency.AttachAndMount, map[string]string{"Metric": "attach_and_mount", "StorageClass": class}))
+	}
+	return perfData
+}
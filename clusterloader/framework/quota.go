@@ -0,0 +1,109 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/kubernetes/pkg/api/v1"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+const (
+	defaultQuotaTimeout = 10 * time.Minute
+	quotaPollInterval   = 2 * time.Second
+)
+
+var (
+	quotaLock    sync.Mutex
+	quotaBlocked time.Duration
+)
+
+// QuotaBlockedTime returns how long pod creation waited for quota in total
+func QuotaBlockedTime() time.Duration {
+	quotaLock.Lock()
+	defer quotaLock.Unlock()
+	return quotaBlocked
+}
+
+// waitForQuota waits until the ResourceQuotas of the namespace have room for a pod with the spec
+func (tuning *TuningSetObject) waitForQuota(c clientset.Interface, namespace string, spec v1.PodSpec) error {
+	timeout := defaultQuotaTimeout
+	if tuning.Quota.Timeout != "" {
+		duration, err := time.ParseDuration(tuning.Quota.Timeout)
+		if err != nil {
+			return err
+		}
+		timeout = duration
+	}
+	start := time.Now()
+	logged := false
+	err := wait.PollImmediate(quotaPollInterval, timeout, func() (bool, error) {
+		quotas, err := c.Core().ResourceQuotas(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, quota := range quotas.Items {
+			if name, ok := quotaExceeded(quota, spec); ok {
+				if !logged {
+					framework.Logf("Quota %v in namespace %v has no room for %v, waiting", quota.Name, namespace, name)
+					logged = true
+				}
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if blocked := time.Since(start); logged {
+		framework.Logf("Waited %v for quota in namespace %v", blocked, namespace)
+		quotaLock.Lock()
+		quotaBlocked += blocked
+		quotaLock.Unlock()
+	}
+	return err
+}
+
+// quotaExceeded returns the resource of the quota a new pod with the spec would exceed
+func quotaExceeded(quota v1.ResourceQuota, spec v1.PodSpec) (v1.ResourceName, bool) {
+	requests := podRequests(spec)
+	demand := map[v1.ResourceName]int64{
+		v1.ResourcePods:           1,
+		v1.ResourceCPU:            requests.cpu,
+		v1.ResourceRequestsCPU:    requests.cpu,
+		v1.ResourceMemory:         requests.memory,
+		v1.ResourceRequestsMemory: requests.memory,
+	}
+	for name, hard := range quota.Status.Hard {
+		needed, ok := demand[name]
+		if !ok {
+			continue
+		}
+		used := quota.Status.Used[name]
+		if name == v1.ResourceCPU || name == v1.ResourceRequestsCPU {
+			if used.MilliValue()+needed > hard.MilliValue() {
+				return name, true
+			}
+		} else if used.Value()+needed > hard.Value() {
+			return name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/api/v1"
+)
+
+func newTestQuota(hard, used v1.ResourceList) v1.ResourceQuota {
+	return v1.ResourceQuota{Status: v1.ResourceQuotaStatus{Hard: hard, Used: used}}
+}
+
+func TestQuotaExceeded(t *testing.T) {
+	spec := v1.PodSpec{
+		Containers: []v1.Container{{
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse("100m"),
+					v1.ResourceMemory: resource.MustParse("64Mi"),
+				},
+			},
+		}},
+	}
+	for _, test := range []struct {
+		name     string
+		quota    v1.ResourceQuota
+		expected v1.ResourceName
+	}{
+		{
+			name:  "room for the pod",
+			quota: newTestQuota(v1.ResourceList{v1.ResourcePods: resource.MustParse("10")}, v1.ResourceList{v1.ResourcePods: resource.MustParse("9")}),
+		},
+		{
+			name:     "no room for the pod",
+			quota:    newTestQuota(v1.ResourceList{v1.ResourcePods: resource.MustParse("10")}, v1.ResourceList{v1.ResourcePods: resource.MustParse("10")}),
+			expected: v1.ResourcePods,
+		},
+		{
+			name:  "nothing used yet",
+			quota: newTestQuota(v1.ResourceList{v1.ResourcePods: resource.MustParse("1")}, nil),
+		},
+		{
+			name:  "cpu fits exactly",
+			quota: newTestQuota(v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse("1")}, v1.ResourceList{v1.ResourceRequestsCPU: resource.MustParse("900m")}),
+		},
+		{
+			name:     "cpu exceeded",
+			quota:    newTestQuota(v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}, v1.ResourceList{v1.ResourceCPU: resource.MustParse("950m")}),
+			expected: v1.ResourceCPU,
+		},
+		{
+			name:     "memory exceeded",
+			quota:    newTestQuota(v1.ResourceList{v1.ResourceRequestsMemory: resource.MustParse("1Gi")}, v1.ResourceList{v1.ResourceRequestsMemory: resource.MustParse("1000Mi")}),
+			expected: v1.ResourceRequestsMemory,
+		},
+		{
+			name:  "other resources are ignored",
+			quota: newTestQuota(v1.ResourceList{v1.ResourceServices: resource.MustParse("0")}, v1.ResourceList{v1.ResourceServices: resource.MustParse("0")}),
+		},
+	} {
+		name, exceeded := quotaExceeded(test.quota, spec)
+		if exceeded != (test.expected != "") || name != test.expected {
+			t.Errorf("%v: expected exceeded resource %q, got %q (exceeded %v)", test.name, test.expected, name, exceeded)
+		}
+	}
+}
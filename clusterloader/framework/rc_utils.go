@@ -27,7 +27,7 @@ import (
 
 // CreateRC will create a new RC if it does not exist, or it will update an existing RC with a new replica count if it does exist
 func CreateRC(f *framework.Framework, name, namespace string, label labels.Set, spec v1.PodSpec, replicas int) error {
-	_, err := f.ClientSet.Core().ReplicationControllers(namespace).Get(name, metav1.GetOptions{})
+	_, err := objectClient(f).Core().ReplicationControllers(namespace).Get(name, metav1.GetOptions{})
 	// If RC is not found, Get() will return a NotFound error
 	if errors.IsNotFound(err) {
 		newRC := newRC(name, int32(replicas), label, spec)
@@ -35,31 +35,48 @@ func CreateRC(f *framework.Framework, name, namespace string, label labels.Set,
 			return err
 		}
 	} else {
-		updateReplicas := func(update *v1.ReplicationController) {
-			x := int32(replicas)
-			update.Spec.Replicas = &x
-		}
-		if _, err := framework.UpdateReplicationControllerWithRetries(f.ClientSet, namespace, name, updateReplicas); err != nil {
+		if err := updateRCReplicasWithRetries(f, name, namespace, int32(replicas)); err != nil {
 			return err
 		}
 	}
+	ObjectsCreated(1)
 
 	// Wait for pods running matching label using podstore
 	// does not take replica count into effect, nor owner reference
 	return kutils.WaitForPodsWithLabelRunning(f.ClientSet, namespace, labels.SelectorFromSet(label))
 }
 
-// createNewRCWithRetries uses polling to retry RC creation
+// createNewRCWithRetries retries RC creation with backoff on transient errors
 func createNewRCWithRetries(f *framework.Framework, name, namespace string, rcObj *v1.ReplicationController) (rc *v1.ReplicationController, err error) {
-	for retryCount := 0; retryCount < maxRetries; retryCount++ {
-		if rc, err = f.ClientSet.Core().ReplicationControllers(namespace).Create(rcObj); err == nil {
-			framework.Logf("Created replication controller %q", name)
-			break
-		}
+	err = retryWithBackoff("creating replication controller "+namespace+"/"+name, func() error {
+		rc, err = objectClient(f).Core().ReplicationControllers(namespace).Create(rcObj)
+		return err
+	})
+	if err == nil {
+		framework.Logf("Created replication controller %q", name)
 	}
 	return
 }
 
+// updateRCReplicasWithRetries sets the replica count of the RC with the same client as the creation,
+// conflicts with concurrent updates are retried with backoff like the other errors
+func updateRCReplicasWithRetries(f *framework.Framework, name, namespace string, replicas int32) error {
+	err := retryWithBackoff("updating replication controller "+namespace+"/"+name, func() error {
+		client := objectClient(f)
+		rc, err := client.Core().ReplicationControllers(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		rc.Spec.Replicas = &replicas
+		_, err = client.Core().ReplicationControllers(namespace).Update(rc)
+		return err
+	})
+	if err == nil {
+		framework.Logf("Updated replication controller %q to %d replicas", name, replicas)
+	}
+	return err
+}
+
 // newRC creates a new ReplicationController config object
 func newRC(rsName string, replicas int32, rcPodLabels map[string]string, spec v1.PodSpec) *v1.ReplicationController {
 	return &v1.ReplicationController{
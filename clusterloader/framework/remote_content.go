@@ -0,0 +1,104 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+const remoteContentTimeout = time.Minute
+
+var (
+	remoteContentLock sync.Mutex
+	// remoteContent maps the URLs fetched by this run to their files in the cache
+	remoteContent = make(map[string]string)
+)
+
+// isRemoteContent returns whether the content file is an http(s) URL
+func isRemoteContent(file string) bool {
+	return strings.HasPrefix(file, "http://") || strings.HasPrefix(file, "https://")
+}
+
+// fetchRemoteContent downloads the URL into the content cache once and returns the cached file.
+// Files already in --content-cache-dir are reused across runs, so URLs should be versioned.
+func fetchRemoteContent(contentURL string) (string, error) {
+	remoteContentLock.Lock()
+	defer remoteContentLock.Unlock()
+	if cached, ok := remoteContent[contentURL]; ok {
+		return cached, nil
+	}
+
+	dir := contentCacheDir
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), fmt.Sprintf("clusterloader-content-%d", os.Getpid()))
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	ext := ""
+	if parsed, err := url.Parse(contentURL); err == nil {
+		ext = path.Ext(parsed.Path)
+	}
+	cached := filepath.Join(dir, fmt.Sprintf("%x%v", sha256.Sum256([]byte(contentURL)), ext))
+	if _, err := os.Stat(cached); err == nil {
+		framework.Logf("Using cached %v for %v", cached, contentURL)
+		remoteContent[contentURL] = cached
+		return cached, nil
+	}
+
+	client := &http.Client{Timeout: remoteContentTimeout}
+	resp, err := client.Get(contentURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetching %v returned %v", contentURL, resp.Status)
+	}
+	// Download next to the cached file and rename, so a failed download never ends up in the cache
+	tmpfile, err := ioutil.TempFile(dir, "download")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmpfile.Name())
+	if _, err := io.Copy(tmpfile, resp.Body); err != nil {
+		tmpfile.Close()
+		return "", err
+	}
+	if err := tmpfile.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmpfile.Name(), cached); err != nil {
+		return "", err
+	}
+	framework.Logf("Fetched %v into %v", contentURL, cached)
+	remoteContent[contentURL] = cached
+	return cached, nil
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+const (
+	throttleStep     = 100 * time.Millisecond
+	maxThrottleDelay = 10 * time.Second
+)
+
+var (
+	retryLock sync.Mutex
+	retries   = make(map[string]int)
+	gaveUp    = make(map[string]int)
+	// throttleDelay slows down all object operations while the apiserver responds with 429s,
+	// it grows with every 429 and halves with every successful operation
+	throttleDelay time.Duration
+	throttled     time.Duration
+)
+
+// throttle waits for the current throttle delay before an object operation
+func throttle() {
+	retryLock.Lock()
+	delay := throttleDelay
+	throttled += delay
+	retryLock.Unlock()
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+// updateThrottle adjusts the throttle delay after an object operation
+func updateThrottle(err error) {
+	retryLock.Lock()
+	defer retryLock.Unlock()
+	if !errors.IsTooManyRequests(err) {
+		if throttleDelay /= 2; throttleDelay < throttleStep {
+			throttleDelay = 0
+		}
+		return
+	}
+	if throttleDelay == 0 {
+		framework.Logf("WARNING: the apiserver is throttling requests, slowing down object operations")
+	}
+	throttleDelay = 2*throttleDelay + throttleStep
+	if seconds, ok := errors.SuggestsClientDelay(err); ok && time.Duration(seconds)*time.Second > throttleDelay {
+		throttleDelay = time.Duration(seconds) * time.Second
+	}
+	if throttleDelay > maxThrottleDelay {
+		throttleDelay = maxThrottleDelay
+	}
+}
+
+// retryReason classifies errors worth retrying, permanent errors like invalid objects return false
+func retryReason(err error) (string, bool) {
+	switch {
+	case errors.IsTooManyRequests(err):
+		return "TooManyRequests", true
+	case errors.IsServerTimeout(err), errors.IsTimeout(err):
+		return "Timeout", true
+	case errors.IsConflict(err):
+		return "Conflict", true
+	case errors.IsInternalError(err):
+		return "InternalError", true
+	}
+	if _, ok := err.(net.Error); ok {
+		// Not an apiserver response, e.g. a refused connection or a client side timeout
+		return "Connection", true
+	}
+	if err == io.ErrUnexpectedEOF || utilnet.IsProbableEOF(err) || utilnet.IsConnectionReset(err) {
+		return "Connection", true
+	}
+	return "", false
+}
+
+// retryWithBackoff retries the operation with exponential backoff as long as it fails with retryable
+// errors, up to --object-retries attempts, and returns the last error if it never succeeded.
+// Every attempt is throttled while the apiserver responds with 429s. A retried create which finds
+// the object already exists succeeds, the failed attempt most likely created it before timing out.
+func retryWithBackoff(operation string, fn func() error) error {
+	var lastErr error
+	var lastReason string
+	backoff := wait.Backoff{Duration: objectRetryBackoff, Factor: 2, Jitter: 0.1, Steps: objectRetries}
+	if backoff.Steps < 1 {
+		backoff.Steps = 1
+	}
+	inflightOperations.Inc()
+	defer inflightOperations.Dec()
+	attempt := 0
+	err := wait.ExponentialBackoff(backoff, func() (bool, error) {
+		attempt++
+		throttle()
+		lastErr = fn()
+		updateThrottle(lastErr)
+		if lastErr == nil {
+			return true, nil
+		}
+		if attempt > 1 && errors.IsAlreadyExists(lastErr) {
+			framework.Logf("Retried %v found it already exists, assuming an earlier attempt succeeded", operation)
+			return true, nil
+		}
+		reason, ok := retryReason(lastErr)
+		if !ok {
+			return false, lastErr
+		}
+		framework.Logf("Retrying %v after %v error: %v", operation, reason, lastErr)
+		lastReason = reason
+		retryLock.Lock()
+		retries[reason]++
+		retryLock.Unlock()
+		return false, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		retryLock.Lock()
+		gaveUp[lastReason]++
+		retryLock.Unlock()
+		err = fmt.Errorf("%v failed after %d attempts: %v", operation, backoff.Steps, lastErr)
+	}
+	if err != nil {
+		progressError()
+	}
+	return err
+}
+
+// RetriesSummary returns the retries of object operations done so far
+func RetriesSummary() *RetrySummary {
+	retryLock.Lock()
+	defer retryLock.Unlock()
+	summary := &RetrySummary{Retries: make(map[string]int), GaveUp: make(map[string]int), Throttled: throttled}
+	for reason, count := range retries {
+		summary.Retries[reason] = count
+	}
+	for reason, count := range gaveUp {
+		summary.GaveUp[reason] = count
+	}
+	return summary
+}
+
+// RetrySummary counts the retries of object operations by the reason of the error, GaveUp counts
+// the operations which still failed after the last attempt and Throttled is the total time object
+// operations were slowed down because of 429s
+type RetrySummary struct {
+	Retries   map[string]int `json:"retries"`
+	GaveUp    map[string]int `json:"gaveUp"`
+	Throttled time.Duration  `json:"throttled"`
+}
+
+// SummaryKind returns the name of the summary
+func (s *RetrySummary) SummaryKind() string {
+	return "Retries"
+}
+
+// PrintHumanReadable prints the summary as a table
+func (s *RetrySummary) PrintHumanReadable() string {
+	var reasons []string
+	for reason := range s.Retries {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 1, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "Throttled by 429s: %v\n", s.Throttled)
+	fmt.Fprintf(w, "Reason\tRetries\tGaveUp\n")
+	for _, reason := range reasons {
+		fmt.Fprintf(w, "%v\t%v\t%v\n", reason, s.Retries[reason], s.GaveUp[reason])
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// PrintJSON prints the summary as json
+func (s *RetrySummary) PrintJSON() string {
+	return framework.PrettyPrintJSON(s)
+}
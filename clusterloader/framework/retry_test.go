@@ -0,0 +1,58 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestRetryReason(t *testing.T) {
+	pods := schema.GroupResource{Resource: "pods"}
+	for _, test := range []struct {
+		name      string
+		err       error
+		reason    string
+		retryable bool
+	}{
+		{"too many requests", errors.NewGenericServerResponse(http.StatusTooManyRequests, "POST", pods, "pod-1", "", 1, false), "TooManyRequests", true},
+		{"server timeout", errors.NewServerTimeout(pods, "create", 1), "Timeout", true},
+		{"timeout", errors.NewTimeoutError("timeout", 1), "Timeout", true},
+		{"conflict", errors.NewConflict(pods, "pod-1", fmt.Errorf("modified")), "Conflict", true},
+		{"internal error", errors.NewInternalError(fmt.Errorf("etcd")), "InternalError", true},
+		{"connection refused", &net.OpError{Op: "dial", Net: "tcp", Err: fmt.Errorf("connection refused")}, "Connection", true},
+		{"unexpected EOF", io.ErrUnexpectedEOF, "Connection", true},
+		{"connection reset", fmt.Errorf("read tcp 10.0.0.1:443: read: connection reset by peer"), "Connection", true},
+		{"EOF", io.EOF, "Connection", true},
+		{"invalid", errors.NewInvalid(schema.GroupKind{Kind: "Pod"}, "pod-1", field.ErrorList{field.Required(field.NewPath("spec"), "")}), "", false},
+		{"not found", errors.NewNotFound(pods, "pod-1"), "", false},
+		{"forbidden", errors.NewForbidden(pods, "pod-1", fmt.Errorf("quota")), "", false},
+		{"other error", fmt.Errorf("no kind is registered"), "", false},
+	} {
+		reason, retryable := retryReason(test.err)
+		if reason != test.reason || retryable != test.retryable {
+			t.Errorf("%v: expected %q (retryable %v), got %q (retryable %v)", test.name, test.reason, test.retryable, reason, retryable)
+		}
+	}
+}
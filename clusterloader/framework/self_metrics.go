@@ -0,0 +1,78 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/tools/metrics"
+)
+
+// Metrics of the Cluster Loader process itself, served on --status-address under /metrics
+// together with the Go runtime and process metrics of the default registry
+var (
+	apiRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "clusterloader_api_requests_total",
+		Help: "API requests sent by Cluster Loader by method and response code.",
+	}, []string{"method", "code"})
+	apiRequestLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "clusterloader_api_request_duration_seconds",
+		Help:    "Latency of the API requests sent by Cluster Loader by verb.",
+		Buckets: prometheus.ExponentialBuckets(0.001, 2, 15),
+	}, []string{"verb"})
+	inflightOperations = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "clusterloader_inflight_operations",
+		Help: "Object operations in progress, including their retries.",
+	})
+	objectsCreated = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "clusterloader_objects_created",
+		Help: "Objects of the projects created so far.",
+	}, func() float64 { return float64(CurrentProgress().Created) })
+	objectsTotal = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "clusterloader_objects_total",
+		Help: "Objects the projects create in total.",
+	}, func() float64 { return float64(CurrentProgress().Total) })
+	operationErrors = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "clusterloader_operation_errors",
+		Help: "Object operations which failed after all retries.",
+	}, func() float64 { return float64(CurrentProgress().Errors) })
+)
+
+type requestLatency struct{}
+
+func (requestLatency) Observe(verb string, u url.URL, latency time.Duration) {
+	apiRequestLatency.WithLabelValues(verb).Observe(latency.Seconds())
+	recordClientLatency(verb, u.Path, latency)
+}
+
+type requestResult struct{}
+
+func (requestResult) Increment(code string, method string, host string) {
+	apiRequests.WithLabelValues(method, code).Inc()
+}
+
+// RegisterSelfMetrics makes all clients of the process record their requests for the metrics and
+// the client latency summary, it must be called before the first request
+func RegisterSelfMetrics() {
+	for _, collector := range []prometheus.Collector{apiRequests, apiRequestLatency, inflightOperations, objectsCreated, objectsTotal, operationErrors} {
+		prometheus.MustRegister(collector)
+	}
+	metrics.Register(requestLatency{}, requestResult{})
+	statusMux.Handle("/metrics", prometheus.Handler())
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+// Span categories recorded by Cluster Loader
+const (
+	PhaseSpan     = "phase"
+	NamespaceSpan = "namespace"
+	ObjectSpan    = "object"
+)
+
+// Timer records how long the phases of the run, the namespaces and the object operations take
+type Timer struct {
+	lock      sync.Mutex
+	start     time.Time
+	spans     []Span
+	observers []func(Span)
+}
+
+// NewTimer returns a timer measuring from now
+func NewTimer() *Timer {
+	return &Timer{start: time.Now()}
+}
+
+// Observe registers a function called with every span when it ends
+func (t *Timer) Observe(observer func(Span)) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.observers = append(t.observers, observer)
+}
+
+// Start starts a span, the returned function ends it
+func (t *Timer) Start(category, name string) func() {
+	start := time.Now()
+	return func() {
+		span := Span{Category: category, Name: name, Start: start, Duration: time.Since(start)}
+		t.lock.Lock()
+		t.spans = append(t.spans, span)
+		observers := t.observers
+		t.lock.Unlock()
+		for _, observer := range observers {
+			observer(span)
+		}
+	}
+}
+
+// Summary returns the spans recorded so far
+func (t *Timer) Summary() *TimingSummary {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return &TimingSummary{Start: t.start, Duration: time.Since(t.start), QuotaBlocked: QuotaBlockedTime(), Spans: append([]Span{}, t.spans...)}
+}
+
+// TimingSummary holds the timing of every recorded span of the run
+type TimingSummary struct {
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+	// QuotaBlocked is the time pod creation waited for room in ResourceQuotas
+	QuotaBlocked time.Duration `json:"quotaBlocked"`
+	Spans        []Span        `json:"spans"`
+}
+
+// Span is a timed part of the run
+type Span struct {
+	Category string        `json:"category"`
+	Name     string        `json:"name"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+}
+
+// SummaryKind returns the name of the summary
+func (s *TimingSummary) SummaryKind() string {
+	return "Timing"
+}
+
+// PrintHumanReadable prints the phases one by one and the namespaces and object operations in aggregate
+func (s *TimingSummary) PrintHumanReadable() string {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 1, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "Total duration: %v, waiting for quota: %v\n", s.Duration, s.QuotaBlocked)
+	fmt.Fprintf(w, "Phase\tStart\tDuration\n")
+	for _, span := range s.Spans {
+		if span.Category == PhaseSpan {
+			fmt.Fprintf(w, "%v\t+%v\t%v\n", span.Name, span.Start.Sub(s.Start), span.Duration)
+		}
+	}
+	fmt.Fprintf(w, "\nCategory\tCount\tTotal\tMax\tMaxName\n")
+	for _, category := range []string{NamespaceSpan, ObjectSpan} {
+		var count int
+		var total time.Duration
+		var max Span
+		for _, span := range s.Spans {
+			if span.Category != category {
+				continue
+			}
+			count++
+			total += span.Duration
+			if span.Duration > max.Duration {
+				max = span
+			}
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", category, count, total, max.Duration, max.Name)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// PrintJSON prints the summary as json
+func (s *TimingSummary) PrintJSON() string {
+	return framework.PrettyPrintJSON(s)
+}
+
+// traceEvent is a complete event of the Chrome trace event format
+type traceEvent struct {
+	Name      string `json:"name"`
+	Category  string `json:"cat"`
+	Phase     string `json:"ph"`
+	Timestamp int64  `json:"ts"`
+	Duration  int64  `json:"dur"`
+	PID       int    `json:"pid"`
+	TID       int    `json:"tid"`
+}
+
+// WriteTrace writes the spans as a Chrome trace which can be loaded in chrome://tracing,
+// every category is shown as a separate thread
+func (s *TimingSummary) WriteTrace(filePath string) error {
+	threads := map[string]int{PhaseSpan: 1, NamespaceSpan: 2, ObjectSpan: 3}
+	trace := struct {
+		TraceEvents []traceEvent `json:"traceEvents"`
+	}{}
+	for _, span := range s.Spans {
+		trace.TraceEvents = append(trace.TraceEvents, traceEvent{
+			Name:      span.Name,
+			Category:  span.Category,
+			Phase:     "X",
+			Timestamp: int64(span.Start.Sub(s.Start) / time.Microsecond),
+			Duration:  int64(span.Duration / time.Microsecond),
+			PID:       1,
+			TID:       threads[span.Category],
+		})
+	}
+	data, err := json.Marshal(trace)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filePath, data, 0644)
+}
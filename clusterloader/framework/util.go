@@ -19,19 +19,23 @@ package framework
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io/ioutil"
+	"math/rand"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/kubernetes/pkg/api/v1"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
 	"k8s.io/kubernetes/test/e2e/framework"
 )
 
-// CreateNSIfNotExists creates a namespace if it is new, otherwise it will return the existing namespace pointer
-func CreateNSIfNotExists(f *framework.Framework, namespaceName string) (*v1.Namespace, error) {
+// CreateNSIfNotExists creates a namespace if it is new, otherwise it will return the existing namespace pointer.
+// The project tuning, which may be nil, paces the deletion of the namespace at the end of the test.
+func CreateNSIfNotExists(f *framework.Framework, namespaceName string, tuning *TuningSetObject) (*v1.Namespace, error) {
 	var ns *v1.Namespace
 	var err error
 	fullNamespace := getNamespace(f, namespaceName)
@@ -40,6 +44,7 @@ func CreateNSIfNotExists(f *framework.Framework, namespaceName string) (*v1.Name
 		if err != nil {
 			return nil, err
 		}
+		addManagedNamespace(ns.Name, tuning)
 		framework.Logf("Created new namespace: %s", namespaceName)
 	} else {
 		ns, err = f.ClientSet.CoreV1().Namespaces().Get(fullNamespace, metav1.GetOptions{})
@@ -51,11 +56,64 @@ func CreateNSIfNotExists(f *framework.Framework, namespaceName string) (*v1.Name
 	return ns, err
 }
 
+// GetNamespaces returns the existing namespaces with the names
+func GetNamespaces(c clientset.Interface, names []string) ([]*v1.Namespace, error) {
+	var namespaces []*v1.Namespace
+	for _, name := range names {
+		ns, err := c.Core().Namespaces().Get(name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		framework.Logf("Using existing namespace %s", name)
+		namespaces = append(namespaces, ns)
+	}
+	return namespaces, nil
+}
+
+// UsesOnlyExistingNamespaces returns whether every project runs in existing namespaces,
+// so the test doesn't need to create any namespace
+func (c *Context) UsesOnlyExistingNamespaces() bool {
+	for _, p := range c.ClusterLoader.Projects {
+		if len(p.Namespaces) == 0 {
+			return false
+		}
+	}
+	return len(c.ClusterLoader.Projects) > 0 && c.ClusterLoader.Warmup.PodsPerNode == 0 && c.ClusterLoader.Churn.Pods == 0
+}
+
+// ApplyNamespaceConfig adds the labels and annotations of the config to the namespace
+func ApplyNamespaceConfig(c clientset.Interface, ns *v1.Namespace, config NamespaceConfig) (*v1.Namespace, error) {
+	changed := false
+	if ns.Labels == nil {
+		ns.Labels = make(map[string]string)
+	}
+	for key, value := range config.Labels {
+		if ns.Labels[key] != value {
+			ns.Labels[key] = value
+			changed = true
+		}
+	}
+	if ns.Annotations == nil {
+		ns.Annotations = make(map[string]string)
+	}
+	for key, value := range config.Annotations {
+		if ns.Annotations[key] != value {
+			ns.Annotations[key] = value
+			changed = true
+		}
+	}
+	if !changed {
+		return ns, nil
+	}
+	return c.Core().Namespaces().Update(ns)
+}
+
 // getNamespace takes the basename from the config and returns the full generated namespace name
 func getNamespace(f *framework.Framework, baseName string) string {
 	existingNamespaces, _ := f.ClientSet.Core().Namespaces().List(metav1.ListOptions{})
 	for _, value := range existingNamespaces.Items {
-		if value.GenerateName == fmt.Sprintf("e2e-tests-%v-", baseName) {
+		// Terminating namespaces of a previous run can't be reused
+		if value.GenerateName == namespaceGenerateName(baseName) && value.DeletionTimestamp == nil {
 			return value.Name
 		}
 	}
@@ -67,7 +125,7 @@ func (cl *ClusterLoaderObject) ParseConfig() (*v1.Pod, error) {
 	pod := &v1.Pod{}
 	// If the file is defined used that as the config
 	if cl.File != "" {
-		configFile, err := ioutil.ReadFile(MakePath(cl.File))
+		configFile, err := ReadContent(MakePath(cl.File))
 		if err != nil {
 			return pod, err
 		}
@@ -92,16 +150,82 @@ func (cl *ClusterLoaderObject) ParseConfig() (*v1.Pod, error) {
 	return pod, nil
 }
 
-// MakePath returns fully qualfied file location as a string
+var (
+	contentLock sync.Mutex
+	// contentCache holds the pod and template files by path, they are used for many namespaces
+	contentCache = make(map[string][]byte)
+)
+
+// ReadContent reads a pod or template file once and returns the cached content afterwards,
+// callers must not modify the returned slice
+func ReadContent(path string) ([]byte, error) {
+	contentLock.Lock()
+	defer contentLock.Unlock()
+	if content, ok := contentCache[path]; ok {
+		return content, nil
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	contentCache[path] = content
+	return content, nil
+}
+
+// MakePath returns fully qualfied file location as a string, http(s) URLs are fetched into the content cache
 func MakePath(file string) string {
 	// Handle an empty filename.
 	if file == "" {
 		framework.Failf("No template file defined!")
 	}
+	if isRemoteContent(file) {
+		cached, err := fetchRemoteContent(file)
+		if err != nil {
+			framework.Failf("Error fetching %v: %v", file, err)
+		}
+		return cached
+	}
 	// TODO: We should enable passing this as a flag instead of hardcoding.
 	return filepath.Join(os.Getenv("GOPATH"), "src/k8s.io/perf-tests/clusterloader/content/", file)
 }
 
+// Count returns the number of objects to create in a namespace, sampled from [min, max] when they are set
+func (cl *ClusterLoaderObject) Count() int {
+	if cl.Min == 0 && cl.Max == 0 {
+		return cl.Number
+	}
+	return cl.Min + countIntn(cl.Max-cl.Min+1)
+}
+
+// meanCount returns the expected number of objects in a namespace
+func (cl *ClusterLoaderObject) meanCount() float64 {
+	if cl.Min == 0 && cl.Max == 0 {
+		return float64(cl.Number)
+	}
+	return float64(cl.Min+cl.Max) / 2
+}
+
+var (
+	countRandLock sync.Mutex
+	countRandom   *rand.Rand
+)
+
+// countIntn samples an object count in [0, n) from the source seeded by --random-seed. The source
+// isn't safe for concurrent use, so it is only used under the lock.
+func countIntn(n int) int {
+	countRandLock.Lock()
+	defer countRandLock.Unlock()
+	if countRandom == nil {
+		seed := randomSeed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		framework.Logf("Sampling object counts with --random-seed=%d", seed)
+		countRandom = rand.New(rand.NewSource(seed))
+	}
+	return countRandom.Intn(n)
+}
+
 // ConvertToLabelSet will convert the string label to a set, while also setting a default value
 func (cl *ClusterLoaderObject) ConvertToLabelSet() (labels.Set, error) {
 	if cl.Label == "" {
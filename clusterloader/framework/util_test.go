@@ -0,0 +1,98 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"sync"
+	"testing"
+)
+
+// resetCountRand makes the next sampled count reseed from --random-seed
+func resetCountRand(seed int64) {
+	countRandLock.Lock()
+	defer countRandLock.Unlock()
+	randomSeed = seed
+	countRandom = nil
+}
+
+func TestCount(t *testing.T) {
+	resetCountRand(1)
+	for _, test := range []struct {
+		name     string
+		object   ClusterLoaderObject
+		min, max int
+		mean     float64
+	}{
+		{"num", ClusterLoaderObject{Number: 5}, 5, 5, 5},
+		{"zero", ClusterLoaderObject{}, 0, 0, 0},
+		{"min and max", ClusterLoaderObject{Min: 2, Max: 6}, 2, 6, 4},
+		{"max only", ClusterLoaderObject{Max: 3}, 0, 3, 1.5},
+		{"equal min and max", ClusterLoaderObject{Min: 4, Max: 4}, 4, 4, 4},
+	} {
+		seen := make(map[int]bool)
+		for i := 0; i < 1000; i++ {
+			count := test.object.Count()
+			if count < test.min || count > test.max {
+				t.Errorf("%v: count %d out of [%d, %d]", test.name, count, test.min, test.max)
+				break
+			}
+			seen[count] = true
+		}
+		if len(seen) != test.max-test.min+1 {
+			t.Errorf("%v: expected every count in [%d, %d] to be sampled, got %v", test.name, test.min, test.max, seen)
+		}
+		if mean := test.object.meanCount(); mean != test.mean {
+			t.Errorf("%v: expected mean count %v, got %v", test.name, test.mean, mean)
+		}
+	}
+}
+
+func TestCountSeed(t *testing.T) {
+	object := ClusterLoaderObject{Min: 0, Max: 100}
+	sample := func() []int {
+		resetCountRand(42)
+		counts := make([]int, 20)
+		for i := range counts {
+			counts[i] = object.Count()
+		}
+		return counts
+	}
+	first, second := sample(), sample()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("the same --random-seed sampled %v and %v", first, second)
+		}
+	}
+}
+
+func TestCountConcurrent(t *testing.T) {
+	resetCountRand(1)
+	object := ClusterLoaderObject{Min: 1, Max: 10}
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if count := object.Count(); count < 1 || count > 10 {
+					t.Errorf("count %d out of [1, 10]", count)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"time"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// Validate checks the config for mistakes which would otherwise only show up in the middle of a run
+func (c *Context) Validate() error {
+	var errs []error
+	if len(c.ClusterLoader.Projects) == 0 {
+		errs = append(errs, fmt.Errorf("no projects defined"))
+	}
+
+	tuningSets := make(map[string]bool)
+	for i, ts := range c.ClusterLoader.TuningSets {
+		field := fmt.Sprintf("tuningsets[%d]", i)
+		if ts.Name == "" {
+			errs = append(errs, fmt.Errorf("%v: name is required", field))
+		}
+		tuningSets[ts.Name] = true
+		errs = append(errs, ts.Project.validate(field+".project", true, false)...)
+		errs = append(errs, ts.Pods.validate(field+".pods", false, true)...)
+		errs = append(errs, ts.Templates.validate(field+".templates", false, false)...)
+	}
+
+	for i, p := range c.ClusterLoader.Projects {
+		field := fmt.Sprintf("projects[%d]", i)
+		if p.Basename == "" {
+			errs = append(errs, fmt.Errorf("%v: basename is required", field))
+		}
+		if p.Number <= 0 && len(p.Namespaces) == 0 {
+			errs = append(errs, fmt.Errorf("%v: num must be positive, got %d", field, p.Number))
+		}
+		if p.Tuning != "" && !tuningSets[p.Tuning] {
+			errs = append(errs, fmt.Errorf("%v: tuning set %q is not defined", field, p.Tuning))
+		}
+		for j, pod := range p.Pods {
+			errs = append(errs, pod.validate(fmt.Sprintf("%v.pods[%d]", field, j), true)...)
+		}
+		for j, rc := range p.RCs {
+			errs = append(errs, rc.validate(fmt.Sprintf("%v.rcs[%d]", field, j), true)...)
+		}
+		for j, template := range p.Templates {
+			errs = append(errs, template.validate(fmt.Sprintf("%v.templates[%d]", field, j), false)...)
+		}
+		errs = append(errs, validateHooks(field+".hooks.postcreate", p.Hooks.PostCreate)...)
+		errs = append(errs, validateHooks(field+".hooks.predelete", p.Hooks.PreDelete)...)
+		errs = append(errs, validateCommands(field+".exec.before", p.Exec.Before)...)
+		errs = append(errs, validateCommands(field+".exec.after", p.Exec.After)...)
+		for j, identity := range p.Identities {
+			if identity.User == "" {
+				errs = append(errs, fmt.Errorf("%v.identities[%d]: user is required", field, j))
+			}
+		}
+	}
+
+	for i, m := range c.ClusterLoader.Measurements {
+		if _, err := NewMeasurement(m); err != nil {
+			errs = append(errs, fmt.Errorf("measurements[%d]: %v", i, err))
+		}
+	}
+	if err := validateDuration(c.ClusterLoader.Warmup.Settle); err != nil {
+		errs = append(errs, fmt.Errorf("warmup.settle: %v", err))
+	}
+	if c.ClusterLoader.Churn.Pods < 0 {
+		errs = append(errs, fmt.Errorf("churn.pods can't be negative, got %d", c.ClusterLoader.Churn.Pods))
+	}
+	if err := validatePositiveDuration(c.ClusterLoader.Churn.Interval); err != nil {
+		errs = append(errs, fmt.Errorf("churn.interval: %v", err))
+	}
+	for _, name := range reservedPushgatewayLabels {
+		if _, ok := c.ClusterLoader.Pushgateway.Labels[name]; ok {
+			errs = append(errs, fmt.Errorf("pushgateway.labels: %v is set by Cluster Loader", name))
+		}
+	}
+	if c.ClusterLoader.Preflight.MaxNodes < 0 {
+		errs = append(errs, fmt.Errorf("preflight.maxnodes can't be negative, got %d", c.ClusterLoader.Preflight.MaxNodes))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func (cl *ClusterLoaderObject) validate(field string, podSpec bool) []error {
+	var errs []error
+	if cl.Number < 0 {
+		errs = append(errs, fmt.Errorf("%v: num can't be negative, got %d", field, cl.Number))
+	}
+	if cl.Max != 0 || cl.Min != 0 {
+		if cl.Number != 0 {
+			errs = append(errs, fmt.Errorf("%v: num can't be combined with min and max", field))
+		}
+		if cl.Min < 0 || cl.Max < cl.Min {
+			errs = append(errs, fmt.Errorf("%v: min and max must satisfy 0 <= min <= max, got %d and %d", field, cl.Min, cl.Max))
+		}
+	}
+	if podSpec && cl.File == "" && (cl.Image == "" || cl.Basename == "") {
+		errs = append(errs, fmt.Errorf("%v: either file or both image and basename are required", field))
+	}
+	if !podSpec && cl.File == "" {
+		errs = append(errs, fmt.Errorf("%v: file is required", field))
+	}
+	return errs
+}
+
+// validate checks the tuning of one section, parallelism and quota are only honoured by the
+// sections they are allowed in
+func (tuning *TuningSetObject) validate(field string, parallelism, quota bool) []error {
+	var errs []error
+	if tuning.Parallelism < 0 {
+		errs = append(errs, fmt.Errorf("%v.parallelism can't be negative, got %d", field, tuning.Parallelism))
+	}
+	if !parallelism && tuning.Parallelism != 0 {
+		errs = append(errs, fmt.Errorf("%v.parallelism is only supported in the project tuning", field))
+	}
+	if !quota && (tuning.Quota.Wait || tuning.Quota.Timeout != "") {
+		errs = append(errs, fmt.Errorf("%v.quota is only supported in the pods tuning", field))
+	}
+	for _, d := range []struct {
+		name  string
+		value string
+	}{
+		{"stepping.pause", tuning.Stepping.Pause},
+		{"stepping.timeout", tuning.Stepping.Timeout},
+		{"ratelimit.delay", tuning.RateLimit.Delay},
+		{"quota.timeout", tuning.Quota.Timeout},
+	} {
+		if err := validateDuration(d.value); err != nil {
+			errs = append(errs, fmt.Errorf("%v.%v: %v", field, d.name, err))
+		}
+	}
+	return errs
+}
+
+func validateHooks(field string, hooks []Hook) []error {
+	var errs []error
+	for i, hook := range hooks {
+		if (hook.Template == "") == (hook.Exec == "") {
+			errs = append(errs, fmt.Errorf("%v[%d]: exactly one of template and exec is required", field, i))
+		}
+	}
+	return errs
+}
+
+func validateCommands(field string, commands []string) []error {
+	var errs []error
+	for i, command := range commands {
+		if command == "" {
+			errs = append(errs, fmt.Errorf("%v[%d]: command is empty", field, i))
+		}
+	}
+	return errs
+}
+
+func validateDuration(d string) error {
+	if d == "" {
+		return nil
+	}
+	_, err := time.ParseDuration(d)
+	return err
+}
+
+// validatePositiveDuration is validateDuration for intervals, which can't be zero or negative
+func validatePositiveDuration(d string) error {
+	if d == "" {
+		return nil
+	}
+	duration, err := time.ParseDuration(d)
+	if err != nil {
+		return err
+	}
+	if duration <= 0 {
+		return fmt.Errorf("must be positive, got %v", duration)
+	}
+	return nil
+}
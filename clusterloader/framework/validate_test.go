@@ -0,0 +1,226 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"strings"
+	"testing"
+)
+
+func validContext() Context {
+	var c Context
+	c.ClusterLoader.Projects = []ClusterLoader{
+		{
+			Number:   1,
+			Basename: "clusterproject",
+			Tuning:   "default",
+			Pods:     []ClusterLoaderObject{{Number: 1, Image: "k8s.gcr.io/pause-amd64:3.0", Basename: "pausepods"}},
+		},
+	}
+	c.ClusterLoader.TuningSets = []TuningSet{{Name: "default"}}
+	return c
+}
+
+func TestValidate(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		modify func(c *Context)
+		// expected is a part of the error, empty when the config is valid
+		expected string
+	}{
+		{
+			name:   "valid",
+			modify: func(c *Context) {},
+		},
+		{
+			name:     "no projects",
+			modify:   func(c *Context) { c.ClusterLoader.Projects = nil },
+			expected: "no projects defined",
+		},
+		{
+			name:     "missing basename",
+			modify:   func(c *Context) { c.ClusterLoader.Projects[0].Basename = "" },
+			expected: "projects[0]: basename is required",
+		},
+		{
+			name:     "no namespaces",
+			modify:   func(c *Context) { c.ClusterLoader.Projects[0].Number = 0 },
+			expected: "projects[0]: num must be positive",
+		},
+		{
+			name: "existing namespaces",
+			modify: func(c *Context) {
+				c.ClusterLoader.Projects[0].Number = 0
+				c.ClusterLoader.Projects[0].Namespaces = []string{"team-a"}
+			},
+		},
+		{
+			name:     "undefined tuning set",
+			modify:   func(c *Context) { c.ClusterLoader.Projects[0].Tuning = "missing" },
+			expected: `projects[0]: tuning set "missing" is not defined`,
+		},
+		{
+			name:     "pod without image",
+			modify:   func(c *Context) { c.ClusterLoader.Projects[0].Pods[0].Image = "" },
+			expected: "projects[0].pods[0]: either file or both image and basename are required",
+		},
+		{
+			name: "template without file",
+			modify: func(c *Context) {
+				c.ClusterLoader.Projects[0].Templates = []ClusterLoaderObject{{Number: 1, Basename: "template"}}
+			},
+			expected: "projects[0].templates[0]: file is required",
+		},
+		{
+			name: "min and max",
+			modify: func(c *Context) {
+				c.ClusterLoader.Projects[0].Pods[0].Number = 0
+				c.ClusterLoader.Projects[0].Pods[0].Min = 1
+				c.ClusterLoader.Projects[0].Pods[0].Max = 3
+			},
+		},
+		{
+			name: "min above max",
+			modify: func(c *Context) {
+				c.ClusterLoader.Projects[0].Pods[0].Number = 0
+				c.ClusterLoader.Projects[0].Pods[0].Min = 3
+				c.ClusterLoader.Projects[0].Pods[0].Max = 1
+			},
+			expected: "min and max must satisfy 0 <= min <= max",
+		},
+		{
+			name:     "num with max",
+			modify:   func(c *Context) { c.ClusterLoader.Projects[0].Pods[0].Max = 3 },
+			expected: "num can't be combined with min and max",
+		},
+		{
+			name:     "invalid tuning duration",
+			modify:   func(c *Context) { c.ClusterLoader.TuningSets[0].Pods.RateLimit.Delay = "5" },
+			expected: "tuningsets[0].pods.ratelimit.delay",
+		},
+		{
+			name:     "negative parallelism",
+			modify:   func(c *Context) { c.ClusterLoader.TuningSets[0].Project.Parallelism = -1 },
+			expected: "tuningsets[0].project.parallelism can't be negative",
+		},
+		{
+			name:     "pods parallelism",
+			modify:   func(c *Context) { c.ClusterLoader.TuningSets[0].Pods.Parallelism = 5 },
+			expected: "tuningsets[0].pods.parallelism is only supported in the project tuning",
+		},
+		{
+			name: "pods quota",
+			modify: func(c *Context) {
+				c.ClusterLoader.TuningSets[0].Pods.Quota.Wait = true
+				c.ClusterLoader.TuningSets[0].Pods.Quota.Timeout = "5m"
+			},
+		},
+		{
+			name:     "templates quota",
+			modify:   func(c *Context) { c.ClusterLoader.TuningSets[0].Templates.Quota.Wait = true },
+			expected: "tuningsets[0].templates.quota is only supported in the pods tuning",
+		},
+		{
+			name:     "project quota timeout",
+			modify:   func(c *Context) { c.ClusterLoader.TuningSets[0].Project.Quota.Timeout = "5m" },
+			expected: "tuningsets[0].project.quota is only supported in the pods tuning",
+		},
+		{
+			name: "hook with template and exec",
+			modify: func(c *Context) {
+				c.ClusterLoader.Projects[0].Hooks.PostCreate = []Hook{{Template: "quota.yaml", Exec: "true"}}
+			},
+			expected: "projects[0].hooks.postcreate[0]: exactly one of template and exec is required",
+		},
+		{
+			name:     "empty exec command",
+			modify:   func(c *Context) { c.ClusterLoader.Projects[0].Exec.After = []string{""} },
+			expected: "projects[0].exec.after[0]: command is empty",
+		},
+		{
+			name:     "identity without user",
+			modify:   func(c *Context) { c.ClusterLoader.Projects[0].Identities = []Identity{{}} },
+			expected: "projects[0].identities[0]: user is required",
+		},
+		{
+			name: "unknown measurement",
+			modify: func(c *Context) {
+				c.ClusterLoader.Measurements = []MeasurementConfig{{Name: "podlatency"}}
+			},
+			expected: `measurements[0]: unknown measurement "podlatency"`,
+		},
+		{
+			name: "unknown measurement param",
+			modify: func(c *Context) {
+				c.ClusterLoader.Measurements = []MeasurementConfig{{Name: "nodeutilization", Params: map[string]string{"intervall": "10s"}}}
+			},
+			expected: `unknown param "intervall"`,
+		},
+		{
+			name: "non-positive measurement interval",
+			modify: func(c *Context) {
+				c.ClusterLoader.Measurements = []MeasurementConfig{{Name: "listlatency", Params: map[string]string{"interval": "0s"}}}
+			},
+			expected: "interval must be positive",
+		},
+		{
+			name:     "invalid warmup settle",
+			modify:   func(c *Context) { c.ClusterLoader.Warmup.Settle = "soon" },
+			expected: "warmup.settle",
+		},
+		{
+			name:     "negative churn",
+			modify:   func(c *Context) { c.ClusterLoader.Churn.Pods = -1 },
+			expected: "churn.pods can't be negative",
+		},
+		{
+			name:     "zero churn interval",
+			modify:   func(c *Context) { c.ClusterLoader.Churn.Interval = "0s" },
+			expected: "churn.interval: must be positive",
+		},
+		{
+			name: "pushgateway labels",
+			modify: func(c *Context) {
+				c.ClusterLoader.Pushgateway.Labels = map[string]string{"test": "density"}
+			},
+		},
+		{
+			name: "reserved pushgateway label",
+			modify: func(c *Context) {
+				c.ClusterLoader.Pushgateway.Labels = map[string]string{"nodes": "5000"}
+			},
+			expected: "pushgateway.labels: nodes is set by Cluster Loader",
+		},
+		{
+			name:     "negative preflight nodes",
+			modify:   func(c *Context) { c.ClusterLoader.Preflight.MaxNodes = -1 },
+			expected: "preflight.maxnodes can't be negative",
+		},
+	} {
+		c := validContext()
+		test.modify(&c)
+		err := c.Validate()
+		switch {
+		case test.expected == "" && err != nil:
+			t.Errorf("%v: unexpected error: %v", test.name, err)
+		case test.expected != "" && err == nil:
+			t.Errorf("%v: expected an error containing %q", test.name, test.expected)
+		case test.expected != "" && !strings.Contains(err.Error(), test.expected):
+			t.Errorf("%v: expected an error containing %q, got %v", test.name, test.expected, err)
+		}
+	}
+}
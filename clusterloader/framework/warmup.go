@@ -0,0 +1,97 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/test/e2e/framework"
+	testutils "k8s.io/kubernetes/test/utils"
+)
+
+const warmupTimeout = 10 * time.Minute
+
+// Warmup creates a wave of pods on every schedulable node, waits for them to run, deletes them
+// and waits for the cluster to settle, so the first project doesn't pay for cold caches.
+// Windows nodes are skipped, the images of the pods are rewritten for the architecture of the nodes.
+func Warmup(f *framework.Framework, warmup WarmupConfig, arch ClusterArch) error {
+	if warmup.PodsPerNode == 0 {
+		return nil
+	}
+	c := f.ClientSet
+	ns, err := f.CreateNamespace("warmup", nil)
+	if err != nil {
+		return err
+	}
+	image := warmup.Image
+	if image == "" {
+		image = framework.GetPauseImageName(c)
+	}
+	label := labels.Set{"purpose": "warmup"}
+	var nodes []v1.Node
+	for _, node := range framework.GetReadySchedulableNodesOrDie(c).Items {
+		if !isWindowsNode(&node) {
+			nodes = append(nodes, node)
+		}
+	}
+	framework.Logf("Warming up %d nodes with %d pods each", len(nodes), warmup.PodsPerNode)
+
+	zero := int64(0)
+	for _, node := range nodes {
+		spec := v1.PodSpec{
+			TerminationGracePeriodSeconds: &zero,
+			NodeSelector:                  map[string]string{metav1.LabelHostname: node.Labels[metav1.LabelHostname]},
+			Containers: []v1.Container{
+				{
+					Name:  "warmup",
+					Image: image,
+				},
+			},
+		}
+		arch.RewritePodSpec(&spec)
+		for i := 0; i < warmup.PodsPerNode; i++ {
+			if _, err := createNewPodWithRetries(f, ns.Name, newPod("warmup-"+node.Name, ns.Name, i, label, spec)); err != nil {
+				return err
+			}
+		}
+	}
+	if err := testutils.WaitForPodsWithLabelRunning(c, ns.Name, labels.SelectorFromSet(label)); err != nil {
+		return err
+	}
+
+	if err := c.Core().Pods(ns.Name).DeleteCollection(metav1.NewDeleteOptions(0), metav1.ListOptions{}); err != nil {
+		return err
+	}
+	err = wait.Poll(5*time.Second, warmupTimeout, func() (bool, error) {
+		pods, err := c.Core().Pods(ns.Name).List(metav1.ListOptions{})
+		if err != nil {
+			return false, err
+		}
+		return len(pods.Items) == 0, nil
+	})
+	if err != nil {
+		return fmt.Errorf("warmup pods were not deleted: %v", err)
+	}
+
+	framework.Logf("Warmup finished, waiting for the cluster to settle")
+	return sleep(warmup.Settle)
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kubernetes/pkg/api/v1"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	"k8s.io/kubernetes/test/e2e/framework"
+	"k8s.io/kubernetes/test/e2e/perftype"
+)
+
+var (
+	podWritesLock sync.Mutex
+	// podWrites holds when the creation of every pod by namespace/name was issued, it is only
+	// kept while a watchlatency measurement runs
+	podWrites map[string]time.Time
+)
+
+// recordPodWrite keeps the time the creation of the pod is issued
+func recordPodWrite(namespace, name string) {
+	podWritesLock.Lock()
+	defer podWritesLock.Unlock()
+	if podWrites != nil {
+		podWrites[namespace+"/"+name] = time.Now()
+	}
+}
+
+func podWriteTime(key string) (time.Time, bool) {
+	podWritesLock.Lock()
+	defer podWritesLock.Unlock()
+	write, ok := podWrites[key]
+	return write, ok
+}
+
+// watchLatencyMeasurement establishes watches on pods and records the delay between Cluster Loader
+// issuing the creation of a pod and every watch delivering the event of it
+type watchLatencyMeasurement struct {
+	watchers  int
+	lock      sync.Mutex
+	latencies []watchLatency
+	stopChs   []chan struct{}
+}
+
+type watchLatency struct {
+	namespace string
+	latency   framework.PodLatencyData
+}
+
+func newWatchLatencyMeasurement(config MeasurementConfig) (*watchLatencyMeasurement, error) {
+	m := &watchLatencyMeasurement{watchers: 1}
+	if watchers, ok := config.Params["watchers"]; ok {
+		value, err := strconv.Atoi(watchers)
+		if err != nil {
+			return nil, err
+		}
+		if value <= 0 {
+			return nil, fmt.Errorf("watchers must be positive, got %d", value)
+		}
+		m.watchers = value
+	}
+	return m, nil
+}
+
+// Start begins recording pod creations and establishes the watches on pods in all namespaces
+func (m *watchLatencyMeasurement) Start(c clientset.Interface) error {
+	podWritesLock.Lock()
+	podWrites = make(map[string]time.Time)
+	podWritesLock.Unlock()
+	for i := 0; i < m.watchers; i++ {
+		m.stopChs = append(m.stopChs, startInformer(&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return c.Core().Pods(metav1.NamespaceAll).List(options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return c.Core().Pods(metav1.NamespaceAll).Watch(options)
+			},
+		}, &v1.Pod{}, cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				m.observe(obj.(*v1.Pod))
+			},
+		}))
+	}
+	return nil
+}
+
+func (m *watchLatencyMeasurement) observe(pod *v1.Pod) {
+	key := pod.Namespace + "/" + pod.Name
+	write, ok := podWriteTime(key)
+	if !ok {
+		return
+	}
+	latency := watchLatency{namespace: pod.Namespace, latency: framework.PodLatencyData{Name: key, Latency: time.Since(write)}}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.latencies = append(m.latencies, latency)
+}
+
+// Stop closes the watches and summarizes the latencies of the pods in the namespaces
+func (m *watchLatencyMeasurement) Stop(namespaces []*v1.Namespace) (framework.TestDataSummary, error) {
+	for _, stopCh := range m.stopChs {
+		close(stopCh)
+	}
+	podWritesLock.Lock()
+	writes := len(podWrites)
+	podWrites = nil
+	podWritesLock.Unlock()
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	nsSet := namespaceSet(namespaces)
+	var latencies []framework.PodLatencyData
+	for _, l := range m.latencies {
+		if nsSet[l.namespace] {
+			latencies = append(latencies, l.latency)
+		}
+	}
+	return &WatchLatencySummary{
+		Watchers: m.watchers,
+		Writes:   writes,
+		Events:   len(latencies),
+		Latency:  latencyMetric(latencies),
+	}, nil
+}
+
+// WatchLatencySummary holds the latency from issuing the creation of a pod until a watch delivered it
+type WatchLatencySummary struct {
+	Watchers int                     `json:"watchers"`
+	Writes   int                     `json:"writes"`
+	Events   int                     `json:"events"`
+	Latency  framework.LatencyMetric `json:"latency"`
+}
+
+// SummaryKind returns the name of the summary
+func (s *WatchLatencySummary) SummaryKind() string {
+	return "WatchLatency"
+}
+
+// PrintHumanReadable prints the summary as a table
+func (s *WatchLatencySummary) PrintHumanReadable() string {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 1, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "Watchers: %v, pod writes: %v, delivered events: %v\n", s.Watchers, s.Writes, s.Events)
+	fmt.Fprintf(w, "Latency\tPerc50\tPerc90\tPerc99\tPerc100\n")
+	fmt.Fprintf(w, "write_to_event\t%v\t%v\t%v\t%v\n", s.Latency.Perc50, s.Latency.Perc90, s.Latency.Perc99, s.Latency.Perc100)
+	w.Flush()
+	return buf.String()
+}
+
+// PrintJSON prints the summary as json
+func (s *WatchLatencySummary) PrintJSON() string {
+	return framework.PrettyPrintJSON(s)
+}
+
+// PerfData converts the watch latency into a perfdash data item
+func (s *WatchLatencySummary) PerfData() *perftype.PerfData {
+	return &perftype.PerfData{
+		Version:   currentPerfDataVersion,
+		DataItems: []perftype.DataItem{latencyToDataItem(s.Latency, map[string]string{"Metric": "write_to_event"})},
+	}
+}
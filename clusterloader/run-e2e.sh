@@ -20,6 +20,52 @@ set -o pipefail
 
 CLUSTERLOADER_ROOT=$(dirname "${BASH_SOURCE}")
 
-cd ${CLUSTERLOADER_ROOT}/e2e/ && go test -c -o e2e.test
-./e2e.test --ginkgo.v=true --ginkgo.focus="Cluster\sLoader" --kubeconfig="${HOME}/.kube/config" --viper-config=../config/test
+# Optional artifact upload, e.g. ARTIFACTS_BUCKET=gs://my-bucket or s3://my-bucket.
+# Everything written to REPORT_DIR is uploaded under ARTIFACTS_PREFIX when the run ends,
+# whether it passed or not. Credentials are taken from ARTIFACTS_CREDENTIALS if set,
+# otherwise from the default gsutil/aws configuration.
+REPORT_DIR=${REPORT_DIR:-}
+ARTIFACTS_BUCKET=${ARTIFACTS_BUCKET:-}
+ARTIFACTS_PREFIX=${ARTIFACTS_PREFIX:-clusterloader/$(date -u +%Y%m%d-%H%M%S)}
+ARTIFACTS_CREDENTIALS=${ARTIFACTS_CREDENTIALS:-}
+ARTIFACTS_UPLOAD_RETRIES=${ARTIFACTS_UPLOAD_RETRIES:-5}
+
+function upload-artifacts() {
+  if [[ -z "${ARTIFACTS_BUCKET}" || -z "${REPORT_DIR}" || ! -d "${REPORT_DIR}" ]]; then
+    return 0
+  fi
+  local destination="${ARTIFACTS_BUCKET%/}/${ARTIFACTS_PREFIX}"
+  local upload
+  case "${ARTIFACTS_BUCKET}" in
+    gs://*)
+      if [[ -n "${ARTIFACTS_CREDENTIALS}" ]]; then
+        gcloud auth activate-service-account --key-file="${ARTIFACTS_CREDENTIALS}"
+      fi
+      upload=(gsutil -m cp -r "${REPORT_DIR}/." "${destination}")
+      ;;
+    s3://*)
+      if [[ -n "${ARTIFACTS_CREDENTIALS}" ]]; then
+        export AWS_SHARED_CREDENTIALS_FILE="${ARTIFACTS_CREDENTIALS}"
+      fi
+      upload=(aws s3 cp --recursive "${REPORT_DIR}" "${destination}")
+      ;;
+    *)
+      echo "Unsupported artifacts bucket ${ARTIFACTS_BUCKET}, expected gs:// or s3://" >&2
+      return 1
+      ;;
+  esac
+  for attempt in $(seq 1 "${ARTIFACTS_UPLOAD_RETRIES}"); do
+    if "${upload[@]}"; then
+      echo "Uploaded artifacts to ${destination}"
+      return 0
+    fi
+    echo "Uploading artifacts failed (attempt ${attempt}/${ARTIFACTS_UPLOAD_RETRIES})" >&2
+    sleep $((attempt * 10))
+  done
+  return 1
+}
 
+trap upload-artifacts EXIT
+
+cd ${CLUSTERLOADER_ROOT}/e2e/ && go test -c -o e2e.test
+./e2e.test --ginkgo.v=true --ginkgo.focus="Cluster\sLoader" --kubeconfig="${HOME}/.kube/config" --viper-config=../config/test ${REPORT_DIR:+--report-dir="${REPORT_DIR}"}